@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/report"
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// environmentSource is the file name reported for the process environment
+const environmentSource = "<environment>"
+
+// envCmd represents the scan env command
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Scan environment variables for secrets that env or printenv would expose",
+	Long: `The env command checks the environment of the current process, or a
+KEY=VALUE dump such as the output of env, printenv or export -p, for variables
+holding secrets. A variable is reported when its assignment matches a scan
+rule, or when its name suggests a secret (TOKEN, PASSWORD, API_KEY, ...) and
+its value looks like one. Values masked by the CI system (***, [MASKED]) are
+not reported, and reported values are never printed.
+
+Variables that must never be exported at all can be listed with
+--never-export, which accepts glob patterns and is checked whatever their value.
+
+To scan a directory called env, use ./env.
+
+Examples:
+  pipeline-guardian scan env
+  pipeline-guardian scan env --file env-dump.txt --output json
+  printenv | pipeline-guardian scan env --file - --never-export 'VAULT_*,AWS_SECRET_ACCESS_KEY'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dumpFile, _ := cmd.Flags().GetString("file")
+		neverExport, _ := cmd.Flags().GetStringSlice("never-export")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		failOnName, _ := cmd.Flags().GetString("fail-on")
+
+		failOn, err := secrets.ParseSeverity(failOnName)
+		if err != nil {
+			logger.Error("invalid --fail-on", "error", err)
+			os.Exit(exitError)
+		}
+		if _, err := parseFormat(outputFormat); err != nil || outputFormat == "template" {
+			logger.Error("invalid --output", "output", outputFormat, "error", err)
+			os.Exit(exitError)
+		}
+
+		// Read the variables from the dump, or take this process's
+		started := time.Now()
+		source := environmentSource
+		vars := secrets.EnvFromEnviron(os.Environ())
+		if dumpFile != "" {
+			var r io.Reader = os.Stdin
+			source = "<stdin>"
+			if dumpFile != "-" {
+				file, err := os.Open(dumpFile)
+				if err != nil {
+					logger.Error("opening environment dump failed", "error", err)
+					os.Exit(exitError)
+				}
+				defer file.Close()
+				r, source = file, dumpFile
+			}
+			if vars, err = secrets.ParseEnv(r); err != nil {
+				logger.Error("reading environment dump failed", "error", err)
+				os.Exit(exitError)
+			}
+		}
+
+		logger.Info("📊 Scanning environment variables", "source", source, "variables", len(vars))
+		findings := secrets.ScanEnv(source, vars, neverExport)
+
+		info := report.Info{ToolVersion: version, Commit: commitSHA(), Started: started, Finished: time.Now()}
+		if err := outputFindings(os.Stdout, findings, outputFormat, info, outputOptions{}); err != nil {
+			logger.Error("writing report failed", "format", outputFormat, "error", err)
+			os.Exit(exitError)
+		}
+
+		failing := 0
+		for _, f := range findings {
+			if f.Severity.AtLeast(failOn) {
+				failing++
+			}
+		}
+		if failing > 0 {
+			logger.Error("🔴 Secrets exposed in environment variables", "findings", failing, "fail_on", failOn)
+			os.Exit(exitFindings)
+		}
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(envCmd)
+
+	envCmd.Flags().StringP("file", "f", "", "Scan a KEY=VALUE dump (env, printenv or export -p output) instead of the process environment, or - for stdin")
+	envCmd.Flags().StringSlice("never-export", nil, "Names or glob patterns of variables that must never be exported (e.g. VAULT_*)")
+	envCmd.Flags().StringP("output", "o", "text", "Output format (text, json, ndjson, csv, sarif, junit, checkstyle, gitlab-secret-detection, codeclimate, github)")
+	envCmd.Flags().String("fail-on", "low", "Exit with code 1 when findings are at or above this severity (low, medium, high, critical)")
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// skipType is the status of files that are scanned but whose findings are
+// dropped by --type
+const skipType = "type"
+
+// scanPaths returns the paths to scan: the arguments followed by the lines
+// of the --files-from file, or the --path directory when there are none. A
+// path of "-" stands for stdin and cannot be combined with other paths.
+func scanPaths(args []string, filesFrom, defaultPath string) ([]string, error) {
+	paths := append([]string(nil), args...)
+	if filesFrom != "" {
+		listed, err := readFileList(filesFrom)
+		if err != nil {
+			return nil, err
+		}
+		if filesFrom == "-" && slices.Contains(paths, "-") {
+			return nil, fmt.Errorf("stdin cannot be both scanned and read by --files-from")
+		}
+		if len(listed) == 0 {
+			return nil, fmt.Errorf("--files-from %s lists no files", filesFrom)
+		}
+		paths = append(paths, listed...)
+	}
+
+	if len(paths) == 0 {
+		return []string{defaultPath}, nil
+	}
+	if len(paths) > 1 && slices.Contains(paths, "-") {
+		return nil, fmt.Errorf("- (stdin) cannot be combined with other paths")
+	}
+	return paths, nil
+}
+
+// readFileList reads a list of paths, one per line, from a file or from
+// stdin for "-". Blank lines are ignored.
+func readFileList(name string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); strings.TrimSpace(line) != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// listFiles prints what a scan of paths would do with each file, without
+// scanning any content: whether a file is scanned, or which ignore pattern,
+// size limit, binary or encryption check or type filter excludes it. With
+// skippedOnly set, scanned files are left out. Paths are shown relative to
+// root.
+func listFiles(w io.Writer, paths []string, root, scanType string, ignorePatterns []string, skippedOnly bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tPATH\tREASON")
+
+	var visited, skipped, dirs int
+	explain := func(d secrets.FileDecision) {
+		if d.Skip == "" && !matchesType(scanType, d.Path) {
+			d.Skip, d.Detail = skipType, "not selected by --type "+scanType
+		}
+		switch {
+		case d.Dir:
+			dirs++
+		case d.Skip != "":
+			skipped++
+		}
+		if d.Skip == "" && skippedOnly {
+			return
+		}
+
+		status := d.Skip
+		if status == "" {
+			status = "scan"
+		}
+		path := d.Path
+		if rel, err := filepath.Rel(root, d.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		if d.Dir {
+			path += string(filepath.Separator)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", status, path, d.Detail)
+	}
+	for _, path := range paths {
+		stats, err := secrets.ExplainDir(path, ignorePatterns, explain)
+		if err != nil {
+			return err
+		}
+		visited += stats.FilesVisited
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d files: %d scanned, %d skipped; %d directories skipped\n",
+		visited, visited-skipped, skipped, dirs)
+	return err
+}
@@ -0,0 +1,44 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"log/slog"
+	"os"
+)
+
+var (
+	verbose bool
+	quiet   bool
+)
+
+// logger writes progress and diagnostics to stderr so that stdout only ever
+// carries the requested report
+var logger = slog.New(newLogHandler(slog.LevelInfo))
+
+// initLogger applies the --verbose and --quiet flags to the logger
+func initLogger() {
+	level := slog.LevelInfo
+	switch {
+	case quiet:
+		level = slog.LevelWarn
+	case verbose:
+		level = slog.LevelDebug
+	}
+	logger = slog.New(newLogHandler(level))
+}
+
+// newLogHandler returns a text handler on stderr that omits timestamps,
+// which only add noise to CI logs that carry their own
+func newLogHandler(level slog.Level) slog.Handler {
+	return slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+}
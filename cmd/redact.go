@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// minLiteralLength is the length below which --literal-file values are not
+// masked, since they would match all over ordinary output
+const minLiteralLength = 4
+
+// redactCmd represents the redact command
+var redactCmd = &cobra.Command{
+	Use:   "redact",
+	Short: "Mask secrets in output piped through it, such as a CI job log",
+	Long: `The redact command copies stdin to stdout line by line, replacing every
+match of the scan rules with ***[rule-id]***, so that secrets printed by a
+build never reach the CI log. Each line is written as soon as it is complete.
+
+Values that no rule recognizes, such as the secrets a job was given, can be
+listed one per line in a file passed with --literal-file.
+
+Examples:
+  make deploy 2>&1 | pipeline-guardian redact
+  ./release.sh | pipeline-guardian redact --literal-file .known-secrets`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		literalFile, _ := cmd.Flags().GetString("literal-file")
+
+		var literals []string
+		if literalFile != "" {
+			var err error
+			if literals, err = readLiterals(literalFile); err != nil {
+				logger.Error("reading --literal-file failed", "error", err)
+				os.Exit(exitError)
+			}
+		}
+
+		redactor := secrets.NewRedactor(os.Stdout, literals)
+		_, err := io.Copy(redactor, os.Stdin)
+		if closeErr := redactor.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			logger.Error("redacting failed", "error", err)
+			os.Exit(exitError)
+		}
+	},
+}
+
+// readLiterals reads the secret values to mask, one per line, skipping
+// blank lines and values too short to mask safely
+func readLiterals(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var literals []string
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		literal := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case literal == "":
+		case len(literal) < minLiteralLength:
+			logger.Warn("not masking a value shorter than the minimum length", "path", path, "line", n, "min_length", minLiteralLength)
+		default:
+			literals = append(literals, literal)
+		}
+	}
+	return literals, scanner.Err()
+}
+
+func init() {
+	rootCmd.AddCommand(redactCmd)
+
+	redactCmd.Flags().String("literal-file", "", "File listing additional secret values to mask, one per line")
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/richiekrich/pipeline-guardian/internal/report"
+)
+
+// outputFormats lists the formats accepted by --output and --report
+var outputFormats = []string{
+	"text", "json", "ndjson", "csv", "sarif", "junit", "checkstyle",
+	"gitlab-secret-detection", "codeclimate", "github", "template",
+}
+
+// reportTarget is one report written by a scan: a format and where to write
+// it, with "-" meaning stdout
+type reportTarget struct {
+	Format string
+	Path   string
+
+	w      io.Writer
+	file   *os.File
+	stream *report.NDJSONWriter // Set for NDJSON reports, which are written while scanning
+	err    error                // First error writing a streamed report
+}
+
+// hasFormat reports whether any of the reports uses a format
+func hasFormat(targets []reportTarget, format string) bool {
+	for _, target := range targets {
+		if target.Format == format {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFormat validates an output format
+func parseFormat(format string) (string, error) {
+	if !slices.Contains(outputFormats, format) {
+		return "", fmt.Errorf("unknown output format %q (expected one of %s)", format, strings.Join(outputFormats, ", "))
+	}
+	return format, nil
+}
+
+// parseReport parses a --report value of the form format=path
+func parseReport(spec string) (reportTarget, error) {
+	format, path, ok := strings.Cut(spec, "=")
+	if !ok || path == "" {
+		return reportTarget{}, fmt.Errorf("invalid report %q (expected format=path, with - for stdout)", spec)
+	}
+	format, err := parseFormat(format)
+	return reportTarget{Format: format, Path: path}, err
+}
+
+// reportTargets returns the reports requested with --report, plus the one
+// selected with --output and --output-file. --output only adds a report
+// alongside --report when it was given explicitly.
+func reportTargets(specs []string, outputFormat, outputFile string, outputChanged bool) ([]reportTarget, error) {
+	var targets []reportTarget
+	for _, spec := range specs {
+		target, err := parseReport(spec)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	if len(targets) == 0 || outputChanged || outputFile != "" {
+		format, err := parseFormat(outputFormat)
+		if err != nil {
+			return nil, err
+		}
+		if outputFile == "" {
+			outputFile = "-"
+		}
+		targets = append(targets, reportTarget{Format: format, Path: outputFile})
+	}
+
+	// Reports would overwrite each other if they shared a destination
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		if seen[target.Path] {
+			if target.Path == "-" {
+				return nil, fmt.Errorf("only one report can be written to stdout")
+			}
+			return nil, fmt.Errorf("more than one report is written to %s", target.Path)
+		}
+		seen[target.Path] = true
+	}
+	return targets, nil
+}
+
+// open creates the report's destination
+func (t *reportTarget) open() error {
+	if t.Path == "-" {
+		t.w = os.Stdout
+		return nil
+	}
+	file, err := os.Create(t.Path)
+	if err != nil {
+		return err
+	}
+	t.file, t.w = file, file
+	return nil
+}
+
+// terminal reports whether the report is written to an interactive terminal
+func (t *reportTarget) terminal() bool {
+	if t.file != nil {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// close closes the report's destination if it is a file
+func (t *reportTarget) close() error {
+	if t.file == nil {
+		return nil
+	}
+	return t.file.Close()
+}
@@ -13,10 +13,15 @@ import (
 
 var cfgFile string
 
+// version is the released version of pipeline-guardian, overridden at build
+// time with -ldflags "-X github.com/richiekrich/pipeline-guardian/cmd.version=..."
+var version = "1.0.0"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "pipeline-guardian",
-	Short: "A DevSecOps tool for securing your CI/CD pipelines",
+	Use:     "pipeline-guardian",
+	Version: version,
+	Short:   "A DevSecOps tool for securing your CI/CD pipelines",
 	Long: `Pipeline Guardian is your DevSecOps sidekick, designed to help you 
 implement security best practices in your CI/CD pipelines.
 
@@ -44,18 +49,22 @@ policies throughout your development and deployment processes.`,
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		// Usage errors are errors, not findings, so they share the scan
+		// command's error exit code
+		os.Exit(exitError)
 	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initLogger, initConfig)
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.pipeline-guardian.yaml)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Log detailed progress to stderr")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only log warnings and errors to stderr")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -82,6 +91,6 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+		logger.Debug("using config file", "path", viper.ConfigFileUsed())
 	}
 }
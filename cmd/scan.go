@@ -4,117 +4,527 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/richiekrich/pipeline-guardian/internal/report"
 	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+	"github.com/richiekrich/pipeline-guardian/internal/verify"
 	"github.com/spf13/cobra"
 )
 
 // scanCmd represents the scan command
 var scanCmd = &cobra.Command{
-	Use:   "scan",
+	Use:   "scan [path...]",
 	Short: "Scan pipeline configuration files for security issues",
 	Long: `The scan command analyzes your pipeline configuration files 
 (GitHub Actions, GitLab CI, Jenkins, etc.) for security vulnerabilities 
 and compliance issues, including accidentally committed secrets or credentials.
 
+Exit codes:
+  0  No findings at or above the --fail-on threshold
+  1  Findings at or above the threshold (at least --fail-on-count of them)
+  2  The scan could not be completed
+
+Paths can be given as arguments, read from a file with --files-from, or
+with --path. Use - to scan stdin.
+
 Examples:
   pipeline-guardian scan --path ./github/workflows
-  pipeline-guardian scan --type github-actions --output json`,
+  pipeline-guardian scan .github/workflows deploy/values.yaml
+  git diff --name-only main | pipeline-guardian scan --files-from -
+  kubectl get secret -o yaml | pipeline-guardian scan - --stdin-filename secret.yaml
+  pipeline-guardian scan --type github-actions --output json
+  pipeline-guardian scan --fail-on critical`,
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		scanPath, _ := cmd.Flags().GetString("path")
 		scanType, _ := cmd.Flags().GetString("type")
 		outputFormat, _ := cmd.Flags().GetString("output")
+		outputFile, _ := cmd.Flags().GetString("output-file")
 		ignorePatterns, _ := cmd.Flags().GetStringSlice("ignore")
+		verifyFindings, _ := cmd.Flags().GetBool("verify")
+		verifyEndpoints, _ := cmd.Flags().GetStringToString("verify-endpoint")
+		verifyInterval, _ := cmd.Flags().GetDuration("verify-interval")
+		failOnName, _ := cmd.Flags().GetString("fail-on")
+		failOnCount, _ := cmd.Flags().GetInt("fail-on-count")
+		baselineFile, _ := cmd.Flags().GetString("baseline")
+		junitGroup, _ := cmd.Flags().GetString("junit-group")
+		templateName, _ := cmd.Flags().GetString("template")
+		reportSpecs, _ := cmd.Flags().GetStringArray("report")
+		colorMode, _ := cmd.Flags().GetString("color")
+		contextLines, _ := cmd.Flags().GetInt("context")
+		strict, _ := cmd.Flags().GetBool("strict")
+		listAll, _ := cmd.Flags().GetBool("list-files")
+		explainSkips, _ := cmd.Flags().GetBool("explain-skips")
+		stdinName, _ := cmd.Flags().GetString("stdin-filename")
+		filesFrom, _ := cmd.Flags().GetString("files-from")
+		watchPaths, _ := cmd.Flags().GetBool("watch")
+
+		// Annotate the pull request when running in GitHub Actions
+		if !cmd.Flags().Changed("output") && os.Getenv("GITHUB_ACTIONS") == "true" {
+			outputFormat = "github"
+		}
+
+		failOn, err := secrets.ParseSeverity(failOnName)
+		if err != nil {
+			logger.Error("invalid --fail-on", "error", err)
+			os.Exit(exitError)
+		}
+		opts := outputOptions{}
+		if opts.JUnitGroup, err = report.ParseGroupBy(junitGroup); err != nil {
+			logger.Error("invalid --junit-group", "error", err)
+			os.Exit(exitError)
+		}
+
+		if colorMode != "auto" && colorMode != "always" && colorMode != "never" {
+			logger.Error("invalid --color", "color", colorMode, "expected", "auto, always or never")
+			os.Exit(exitError)
+		}
+		opts.Text.Context = max(0, contextLines)
+
+		paths, err := scanPaths(args, filesFrom, scanPath)
+		if err != nil {
+			logger.Error("invalid paths", "error", err)
+			os.Exit(exitError)
+		}
+		readStdin := len(paths) == 1 && paths[0] == "-"
+
+		// List paths relative to a single scanned directory, or to the
+		// directory of a single scanned file, and otherwise as given
+		root := "."
+		if len(paths) == 1 && !readStdin {
+			root = paths[0]
+			if info, err := os.Stat(root); err == nil && !info.IsDir() {
+				root = filepath.Dir(root)
+			}
+		}
+
+		targets, err := reportTargets(reportSpecs, outputFormat, outputFile, cmd.Flags().Changed("output"))
+		if err != nil {
+			logger.Error("invalid report", "error", err)
+			os.Exit(exitError)
+		}
+
+		// Reports point at files relative to the repository, as code
+		// scanning tools expect, whichever part of it was scanned
+		info := report.Info{ToolVersion: version, Root: repositoryRoot(paths[0]), Commit: commitSHA()}
+		if hasFormat(targets, "template") {
+			if templateName == "" {
+				logger.Error("--output template requires --template", "builtin", report.BuiltinTemplates())
+				os.Exit(exitError)
+			}
+			if opts.Template, err = report.ParseTemplate(templateName, info); err != nil {
+				logger.Error("invalid --template", "error", err)
+				os.Exit(exitError)
+			}
+		}
+
+		// Findings accepted earlier are suppressed rather than reported
+		var baseline report.Baseline
+		if baselineFile != "" {
+			file, err := os.Open(baselineFile)
+			if err == nil {
+				baseline, err = report.ReadBaseline(file)
+				file.Close()
+			}
+			if err != nil {
+				logger.Error("invalid --baseline", "path", baselineFile, "error", err)
+				os.Exit(exitError)
+			}
+		}
+
+		// Verify paths exist
+		for _, path := range paths {
+			if _, err := os.Stat(path); os.IsNotExist(err) && path != "-" {
+				logger.Error("path does not exist", "path", path)
+				os.Exit(exitError)
+			}
+		}
+
+		// Only show what would be scanned
+		if listAll || explainSkips {
+			if readStdin {
+				logger.Error("--list-files and --explain-skips cannot be used with stdin")
+				os.Exit(exitError)
+			}
+			if err := listFiles(os.Stdout, paths, root, scanType, ignorePatterns, !listAll); err != nil {
+				logger.Error("listing files failed", "error", err)
+				os.Exit(exitError)
+			}
+			return
+		}
+
+		// Keep re-scanning changed files until interrupted
+		if watchPaths {
+			if readStdin {
+				logger.Error("--watch cannot be used with stdin")
+				os.Exit(exitError)
+			}
+			if len(targets) != 1 || targets[0].Format != "text" || targets[0].Path != "-" {
+				logger.Error("--watch only writes text output to stdout")
+				os.Exit(exitError)
+			}
+			if err := watchScan(paths, info, baseline, scanType, ignorePatterns, textOptions(opts, &targets[0], colorMode).Text); err != nil {
+				logger.Error("watching failed", "error", err)
+				os.Exit(exitError)
+			}
+			return
+		}
+
+		// Open every report destination before spending time on the scan
+		for i := range targets {
+			if err := targets[i].open(); err != nil {
+				logger.Error("creating output file failed", "path", targets[i].Path, "error", err)
+				os.Exit(exitError)
+			}
+		}
 
-		// Verify path exists
-		if _, err := os.Stat(scanPath); os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "Error: Path '%s' does not exist\n", scanPath)
-			os.Exit(1)
+		verifyOptions := verify.Options{BaseURLs: verifyEndpoints, Interval: verifyInterval}
+
+		// NDJSON is written while scanning instead of once all findings are
+		// in, and findings are only collected for the other formats
+		var session *verify.Session
+		collect := false
+		for i := range targets {
+			if targets[i].Format == "ndjson" {
+				targets[i].stream = report.NewNDJSONWriter(targets[i].w, info)
+				if verifyFindings && session == nil {
+					session = verify.NewSession(verifyOptions)
+				}
+			} else {
+				collect = true
+			}
 		}
 
-		fmt.Println("📊 Scanning for security issues...")
-		fmt.Printf("Path: %s\n", scanPath)
-		fmt.Printf("Type: %s\n", scanType)
-		fmt.Printf("Output format: %s\n", outputFormat)
+		logger.Info("📊 Scanning for security issues", "paths", paths, "type", scanType, "reports", len(targets))
 
 		// Perform the secret/credential scan
-		findings, err := secrets.ScanDir(scanPath, ignorePatterns)
+		started := time.Now()
+		var findings, suppressed []secrets.Finding
+		total, failing := 0, 0
+		onFinding := func(f secrets.Finding) {
+			// Filter findings based on scan type if specified
+			if !matchesType(scanType, f.File) {
+				return
+			}
+
+			// Suppressed findings neither fail the scan nor appear in
+			// reports, except as suppressed results in SARIF
+			baseline.Suppress(&f, info)
+			if f.Suppression != "" {
+				suppressed = append(suppressed, f)
+				return
+			}
+			total++
+			if f.Severity.AtLeast(failOn) {
+				failing++
+			}
+
+			if session != nil {
+				session.Verify(context.Background(), &f)
+			}
+			if collect {
+				findings = append(findings, f)
+			}
+			for i := range targets {
+				if targets[i].stream != nil && targets[i].err == nil {
+					targets[i].err = targets[i].stream.WriteFinding(f)
+				}
+			}
+		}
+		var stats secrets.Stats
+		if readStdin {
+			stats, err = secrets.ScanReaderFunc(stdinName, os.Stdin, onFinding)
+		} else {
+			stats, err = secrets.ScanPathsFunc(paths, ignorePatterns, onFinding)
+		}
+		if session != nil {
+			session.Close()
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error scanning for secrets: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Filter findings based on scan type if specified
-		if scanType != "auto" && scanType != "" {
-			// Apply scan type-specific filtering (e.g., only GitHub Actions files)
-			filteredFindings := []secrets.Finding{}
-			for _, f := range findings {
-				// For GitHub Actions, focus on workflow files
-				if scanType == "github-actions" && (strings.HasSuffix(f.File, ".yml") || strings.HasSuffix(f.File, ".yaml")) {
-					filteredFindings = append(filteredFindings, f)
-				} else if scanType == "gitlab-ci" && strings.HasSuffix(f.File, ".gitlab-ci.yml") {
-					filteredFindings = append(filteredFindings, f)
-				} else if scanType == "jenkins" && strings.HasSuffix(f.File, "Jenkinsfile") {
-					filteredFindings = append(filteredFindings, f)
-				} else if scanType == "all" {
-					filteredFindings = append(filteredFindings, f)
+			logger.Error("scanning for secrets failed", "error", err)
+			os.Exit(exitError)
+		}
+		logger.Debug("scan finished", "findings", total, "files", stats.FilesScanned, "skipped", stats.Skipped)
+		if len(suppressed) > 0 {
+			logger.Info("findings suppressed inline or by the baseline", "suppressed", len(suppressed))
+		}
+		for _, err := range stats.Errors {
+			logger.Warn("could not read path", "path", err.Path, "op", err.Op, "error", err.Unwrap())
+		}
+
+		// Check whether the detected credentials are still live
+		if verifyFindings && session == nil {
+			logger.Info("🔑 Verifying detected credentials", "findings", len(findings))
+			verify.Findings(context.Background(), findings, verifyOptions)
+		}
+
+		// Write each report, or end its stream with the summary record
+		info.Started, info.Finished, info.Stats = started, time.Now(), stats
+		failed := false
+		for _, target := range targets {
+			err := target.err
+			if err == nil && target.stream != nil {
+				err = target.stream.WriteSummary(info)
+			} else if err == nil {
+				reported := findings
+				if target.Format == "sarif" {
+					reported = append(slices.Clone(findings), suppressed...)
 				}
+				err = outputFindings(target.w, reported, target.Format, info, textOptions(opts, &target, colorMode))
 			}
-			findings = filteredFindings
+			if closeErr := target.close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				logger.Error("writing report failed", "format", target.Format, "path", target.Path, "error", err)
+				failed = true
+			} else if target.file != nil {
+				logger.Info("report written", "format", target.Format, "path", target.Path)
+			}
+		}
+		if failed {
+			os.Exit(exitError)
+		}
+		if hasFormat(targets, "github") {
+			writeStepSummary(findings, info)
 		}
 
-		// Output findings based on format
-		outputFindings(findings, outputFormat)
+		// In strict mode a scan that missed paths did not complete
+		if strict && len(stats.Errors) > 0 {
+			logger.Error("🔴 Some paths could not be scanned", "errors", len(stats.Errors))
+			os.Exit(exitError)
+		}
+
+		// Gate on findings at or above the threshold and only warn on the rest
+		if failing >= failOnCount && failing > 0 {
+			logger.Error("🔴 Findings at or above the failure threshold",
+				"findings", failing, "fail_on", failOn, "fail_on_count", failOnCount)
+			os.Exit(exitFindings)
+		}
+		if total > 0 {
+			logger.Warn("⚠️ Review these potential issues and ensure no sensitive information is committed",
+				"findings", total)
+		}
 	},
 }
 
-// outputFindings prints the findings in the specified format
-func outputFindings(findings []secrets.Finding, format string) {
-	if len(findings) == 0 {
-		fmt.Println("✅ Scan completed. No security issues found.")
+// Exit codes of the scan command. A scan without findings at or above the
+// threshold returns normally and exits with 0.
+const (
+	exitFindings = 1 // Findings at or above the threshold
+	exitError    = 2 // The scan could not be completed
+)
+
+// matchesType reports whether a file belongs to the pipeline type selected
+// with --type. Every file matches "auto" and "all".
+func matchesType(scanType, path string) bool {
+	switch scanType {
+	case "auto", "all", "":
+		return true
+	case "github-actions":
+		// For GitHub Actions, focus on workflow files
+		return strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml")
+	case "gitlab-ci":
+		return strings.HasSuffix(path, ".gitlab-ci.yml")
+	case "jenkins":
+		return strings.HasSuffix(path, "Jenkinsfile")
+	}
+	return false
+}
+
+// writeStepSummary appends a Markdown summary of the findings to the
+// GitHub Actions job summary, if the runner provides one
+func writeStepSummary(findings []secrets.Finding, info report.Info) {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		logger.Warn("opening job summary failed", "path", path, "error", err)
 		return
 	}
+	err = report.WriteGitHubSummary(file, findings, info)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.Warn("writing job summary failed", "path", path, "error", err)
+	}
+}
+
+// repositoryRoot returns the top level of the git repository containing
+// path, or the current directory when path is not in a repository or git is
+// not installed. Stdin ("-") belongs to the current directory.
+func repositoryRoot(path string) string {
+	dir := "."
+	if path != "-" {
+		dir = path
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+	}
+
+	if out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output(); err == nil {
+		if root := strings.TrimSpace(string(out)); root != "" {
+			return filepath.FromSlash(root)
+		}
+	}
+	if wd, err := os.Getwd(); err == nil {
+		return wd
+	}
+	return "."
+}
+
+// commitSHA returns the commit being scanned as reported by the CI platform
+func commitSHA() string {
+	for _, name := range []string{"CI_COMMIT_SHA", "GITHUB_SHA", "GIT_COMMIT"} {
+		if sha := os.Getenv(name); sha != "" {
+			return sha
+		}
+	}
+	return ""
+}
 
-	fmt.Printf("🔴 Found %d potential security issues:\n", len(findings))
+// outputOptions holds the format-specific settings of the scan command
+type outputOptions struct {
+	JUnitGroup string             // Group JUnit testcases by file or by rule
+	Template   *template.Template // Template for the template format
+	Text       report.TextOptions // Colors and context of the terminal text format
+	Rich       bool               // Use the terminal text format for text output
+}
 
+// textOptions decides how a text report is rendered. The rich terminal
+// layout is used on a terminal and whenever colors are forced; colors are
+// used on a terminal unless NO_COLOR is set, and always with --color=always.
+// Templates use the same color setting.
+func textOptions(opts outputOptions, target *reportTarget, colorMode string) outputOptions {
+	terminal := target.terminal()
+	opts.Rich = terminal || colorMode == "always"
+	opts.Text.Color = colorMode == "always" ||
+		(colorMode == "auto" && terminal && os.Getenv("NO_COLOR") == "")
+	return opts
+}
+
+// outputFindings writes the findings to w in the specified format. Only the
+// report itself is written; progress and advice go to the logger.
+func outputFindings(w io.Writer, findings []secrets.Finding, format string, info report.Info, opts outputOptions) error {
 	switch format {
 	case "json":
-		// Output as JSON
-		jsonOutput, _ := json.MarshalIndent(findings, "", "  ")
-		fmt.Println(string(jsonOutput))
+		// Output as a versioned JSON envelope with scan metadata
+		return report.WriteJSON(w, findings, info)
+
+	case "sarif":
+		// Output as SARIF for code-scanning integrations
+		return report.WriteSARIF(w, findings, info)
+
+	case "junit":
+		// Output as JUnit XML for CI test result views
+		return report.WriteJUnit(w, findings, info, opts.JUnitGroup)
+
+	case "checkstyle":
+		// Output as Checkstyle XML for CI warnings views
+		return report.WriteCheckstyle(w, findings, info)
+
+	case "github":
+		// Output as GitHub Actions workflow commands for inline annotations
+		return report.WriteGitHubAnnotations(w, findings, info)
+
+	case "gitlab-secret-detection":
+		// Output as a GitLab secret detection report for the security widget
+		return report.WriteGitLabSecretDetection(w, findings, info)
+
+	case "codeclimate":
+		// Output as Code Climate JSON for GitLab's code quality widget
+		return report.WriteCodeClimate(w, findings, info)
+
+	case "template":
+		// Output through a user-defined or built-in template
+		return report.WriteTemplate(w, opts.Template, findings, info, opts.Text.Color)
+
+	case "ndjson":
+		// Output as newline-delimited JSON ending with a summary record
+		return report.WriteNDJSON(w, findings, info)
 
 	case "csv":
 		// Output as CSV
-		fmt.Println("File,Rule,LineNumber,LineContent")
+		fmt.Fprintln(w, "File,Rule,LineNumber,LineContent")
 		for _, f := range findings {
 			// Escape quotes in line content for CSV
 			lineContent := strings.ReplaceAll(f.LineText, "\"", "\"\"")
-			fmt.Printf("\"%s\",\"%s\",%d,\"%s\"\n", f.File, f.Rule, f.LineNum, lineContent)
+			fmt.Fprintf(w, "\"%s\",\"%s\",%d,\"%s\"\n", f.File, f.Rule, f.LineNum, lineContent)
 		}
 
 	default: // "text" format
-		// Output as formatted text
+		// Output grouped by file with context when a person is reading
+		if opts.Rich {
+			return report.WriteRichText(w, findings, info, opts.Text)
+		}
+
+		// Output as formatted text followed by the scan summary
+		if len(findings) == 0 {
+			fmt.Fprintf(w, "✅ Scan completed. No security issues found.\n\n")
+			return report.WriteScanSummary(w, findings, info)
+		}
+
+		fmt.Fprintf(w, "🔴 Found %d potential security issues:\n", len(findings))
 		for i, f := range findings {
-			fmt.Printf("%d) %s (line %d)\n", i+1, f.Rule, f.LineNum)
-			fmt.Printf("   File: %s\n", f.File)
-			fmt.Printf("   Content: %s\n\n", f.LineText)
+			if f.EndLine > f.LineNum {
+				fmt.Fprintf(w, "%d) %s (lines %d-%d)\n", i+1, f.Rule, f.LineNum, f.EndLine)
+			} else {
+				fmt.Fprintf(w, "%d) %s (line %d)\n", i+1, f.Rule, f.LineNum)
+			}
+			fmt.Fprintf(w, "   File: %s\n", f.File)
+			fmt.Fprintf(w, "   Severity: %s\n", f.Severity)
+			if f.Verification != "" {
+				fmt.Fprintf(w, "   Verification: %s\n", f.Verification)
+			}
+			for _, r := range f.Related {
+				fmt.Fprintf(w, "   Related: %s (line %d)\n", r.Rule, r.LineNum)
+			}
+			fmt.Fprintf(w, "   Content: %s\n\n", f.LineText)
 		}
+		return report.WriteScanSummary(w, findings, info)
 	}
 
-	fmt.Println("\n⚠️ Warning: Review these potential issues and ensure no sensitive information is committed.")
-	fmt.Println("   Remember to add any false positives to your .gitignore or use a tool like git-secrets.")
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(scanCmd)
 
 	// Define flags for the scan command
-	scanCmd.Flags().StringP("path", "p", ".", "Path to the directory containing pipeline configuration files")
+	scanCmd.Flags().StringP("path", "p", ".", "Path to the directory containing pipeline configuration files, used when no paths are given as arguments")
+	scanCmd.Flags().String("files-from", "", "Scan the files and directories listed one per line in this file, or - for stdin")
+	scanCmd.Flags().String("stdin-filename", "<stdin>", "File name reported for, and matched by rules against, content scanned from stdin")
 	scanCmd.Flags().StringP("type", "t", "auto", "Type of pipeline (github-actions, gitlab-ci, jenkins, all, etc.)")
-	scanCmd.Flags().StringP("output", "o", "text", "Output format (text, json, csv)")
+	scanCmd.Flags().StringP("output", "o", "text", "Output format (text, json, csv, sarif, junit, checkstyle, gitlab-secret-detection, codeclimate, github, template, ndjson; github is the default in GitHub Actions)")
+	scanCmd.Flags().String("junit-group", report.GroupByFile, "Group JUnit testcases by file or rule")
+	scanCmd.Flags().String("template", "", "Template file or built-in template name for --output template (csv, markdown, slack, summary)")
+	scanCmd.Flags().String("color", "auto", "Colorize text and template output: auto (on a terminal unless NO_COLOR is set), always or never")
+	scanCmd.Flags().Int("context", 2, "Lines of context shown around findings in terminal text output")
+	scanCmd.Flags().String("output-file", "", "Write the report to a file instead of stdout")
+	scanCmd.Flags().StringArray("report", nil, "Write an additional report as format=path, with - for stdout (repeatable)")
 	scanCmd.Flags().StringSliceP("ignore", "i", []string{".git", "node_modules", "vendor", "*.jpg", "*.png", "*.gif"}, "Patterns to ignore")
+	scanCmd.Flags().Bool("verify", false, "Check whether detected credentials are live by calling the issuing service")
+	scanCmd.Flags().StringToString("verify-endpoint", nil, "Override the base URL of a verifier by rule ID (e.g. github-token=https://github.example.com/api/v3)")
+	scanCmd.Flags().String("fail-on", "low", "Exit with code 1 when findings are at or above this severity (low, medium, high, critical)")
+	scanCmd.Flags().Int("fail-on-count", 1, "Minimum number of findings at or above --fail-on needed to exit with code 1")
+	scanCmd.Flags().Bool("list-files", false, "List every file with whether it would be scanned or why it is skipped, without scanning")
+	scanCmd.Flags().Bool("explain-skips", false, "List only the files and directories a scan would skip and why, without scanning")
+	scanCmd.Flags().Bool("watch", false, "After the initial scan, re-scan files as they change and print findings that appear or are resolved")
+	scanCmd.Flags().String("baseline", "", "SARIF log of accepted findings, which are not reported again (SARIF lists them as suppressed)")
+	scanCmd.Flags().Bool("strict", false, "Exit with code 2 when any file or directory could not be read")
+	scanCmd.Flags().Duration("verify-interval", 500*time.Millisecond, "Minimum delay between verification requests")
 }
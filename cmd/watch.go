@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/report"
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+	"github.com/richiekrich/pipeline-guardian/internal/watch"
+)
+
+// watchScan scans paths, then re-scans files as they are created, modified
+// or removed and prints the findings that appear or are resolved, until
+// interrupted. Findings suppressed inline or by the baseline are left out.
+func watchScan(paths []string, info report.Info, baseline report.Baseline, scanType string, ignorePatterns []string, opts report.TextOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	watcher := watch.New(paths, watch.Options{
+		Ignore: ignorePatterns,
+		Filter: func(f secrets.Finding) bool {
+			baseline.Suppress(&f, info)
+			return f.Suppression == "" && matchesType(scanType, f.File)
+		},
+		OnError: func(err error) {
+			logger.Warn("watching for changes failed", "error", err)
+		},
+	})
+
+	logger.Info("📊 Scanning for security issues", "paths", paths, "type", scanType)
+	return watcher.Run(ctx, func(update watch.Update) {
+		info.Stats = update.Stats
+		for _, err := range update.Stats.Errors {
+			logger.Warn("could not read path", "path", err.Path, "op", err.Op, "error", err.Unwrap())
+		}
+		if err := report.WriteChanges(os.Stdout, update.Appeared, update.Resolved, info, opts); err != nil {
+			logger.Error("writing findings failed", "error", err)
+		}
+
+		if update.Initial {
+			logger.Info("👀 Watching for changes", "findings", update.Total, "files", update.Stats.FilesScanned)
+			return
+		}
+		logger.Info("🔁 Re-scanned changed files", "at", time.Now().Format(time.TimeOnly), "paths", len(update.Paths),
+			"new", len(update.Appeared), "resolved", len(update.Resolved), "findings", update.Total)
+	})
+}
@@ -0,0 +1,45 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// Baseline holds the fingerprints of findings that were reviewed and
+// accepted, so that later scans only report new findings. It is read from a
+// SARIF log of an earlier scan.
+type Baseline map[string]bool
+
+// ReadBaseline reads the fingerprints of the results in a SARIF log. Results
+// that were already suppressed are included, so a log written with a
+// baseline can be used as the next one.
+func ReadBaseline(r io.Reader) (Baseline, error) {
+	var log sarifLog
+	if err := json.NewDecoder(r).Decode(&log); err != nil {
+		return nil, fmt.Errorf("reading baseline: %w", err)
+	}
+	if log.Version != "2.1.0" {
+		return nil, fmt.Errorf("reading baseline: not a SARIF 2.1.0 log")
+	}
+
+	baseline := make(Baseline)
+	for _, run := range log.Runs {
+		for _, result := range run.Results {
+			if fingerprint := result.PartialFingerprints[sarifFingerprint]; fingerprint != "" {
+				baseline[fingerprint] = true
+			}
+		}
+	}
+	return baseline, nil
+}
+
+// Suppress marks a finding of the scan described by info as suppressed when
+// the baseline contains it and it is not already suppressed inline
+func (b Baseline) Suppress(f *secrets.Finding, info Info) {
+	if f.Suppression == "" && b[info.fingerprint(*f)] {
+		f.Suppression = secrets.SuppressionBaseline
+	}
+}
@@ -0,0 +1,51 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+func TestBaseline(t *testing.T) {
+	// A baseline is the SARIF log of an earlier scan in another checkout,
+	// including the findings that scan already suppressed
+	earlier, suppressed := testFindings[0], testFindings[1]
+	earlier.File = "/home/runner/work/app/config/deploy.yml"
+	suppressed.File = earlier.File
+	suppressed.Suppression = secrets.SuppressionInline
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, []secrets.Finding{earlier, suppressed}, Info{Root: "/home/runner/work/app"}); err != nil {
+		t.Fatalf("WriteSARIF failed: %v", err)
+	}
+	baseline, err := ReadBaseline(&buf)
+	if err != nil {
+		t.Fatalf("ReadBaseline failed: %v", err)
+	}
+
+	info := Info{Root: "/repo"}
+	accepted, moved, pair, inline, other := testFindings[0], testFindings[0], testFindings[1], testFindings[0], testFindings[2]
+	moved.LineNum = 40
+	inline.Suppression = secrets.SuppressionInline
+	for _, f := range []*secrets.Finding{&accepted, &moved, &pair, &inline, &other} {
+		baseline.Suppress(f, info)
+	}
+
+	if accepted.Suppression != secrets.SuppressionBaseline || moved.Suppression != secrets.SuppressionBaseline {
+		t.Errorf("Expected the accepted finding to be suppressed wherever it moved, got %q and %q", accepted.Suppression, moved.Suppression)
+	}
+	if pair.Suppression != secrets.SuppressionBaseline {
+		t.Errorf("Expected a finding suppressed in the earlier scan to stay suppressed, got %q", pair.Suppression)
+	}
+	if inline.Suppression != secrets.SuppressionInline {
+		t.Errorf("Expected an inline suppression to be kept, got %q", inline.Suppression)
+	}
+	if other.Suppression != "" {
+		t.Errorf("Expected a new finding not to be suppressed, got %q", other.Suppression)
+	}
+
+	if _, err := ReadBaseline(strings.NewReader(`[{"File": "config/deploy.yml"}]`)); err == nil {
+		t.Errorf("Expected an error for a file that is not a SARIF log")
+	}
+}
@@ -0,0 +1,30 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// WriteChanges writes the findings that appeared and were resolved between
+// two scans, such as the re-scans of a watched tree, one line each: "+" for
+// a new finding and "-" for a resolved one
+func WriteChanges(w io.Writer, appeared, resolved []secrets.Finding, info Info, opts TextOptions) error {
+	style := textStyle{color: opts.Color}
+	var b strings.Builder
+
+	location := func(f secrets.Finding) string {
+		return fmt.Sprintf("%s:%d", info.relPath(f.File), f.LineNum)
+	}
+	for _, f := range appeared {
+		fmt.Fprintf(&b, "+ %s  %s  %s\n", style.severity(f.Severity, fmt.Sprintf("%-8s", f.Severity)), location(f), f.Rule)
+	}
+	for _, f := range resolved {
+		b.WriteString(style.dim(fmt.Sprintf("- %-8s  %s  %s (resolved)", f.Severity, location(f), f.Rule)) + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,19 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteChanges(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteChanges(&buf, testFindings[:1], testFindings[2:], Info{Root: "/repo"}, TextOptions{}); err != nil {
+		t.Fatalf("WriteChanges failed: %v", err)
+	}
+
+	expected := "+ low       config/deploy.yml:22  AWS Access Key\n" +
+		"- critical  keys/sa.json:1  GCP Service Account Key (resolved)\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
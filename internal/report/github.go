@@ -0,0 +1,92 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// githubLevels maps severities onto GitHub Actions annotation commands
+var githubLevels = map[secrets.Severity]string{
+	secrets.SeverityLow:      "notice",
+	secrets.SeverityMedium:   "warning",
+	secrets.SeverityHigh:     "error",
+	secrets.SeverityCritical: "error",
+}
+
+// githubDataEscaper escapes the message of a workflow command
+var githubDataEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// githubPropertyEscaper escapes the properties of a workflow command
+var githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// WriteGitHubAnnotations writes findings as GitHub Actions workflow commands,
+// which the runner turns into annotations on the pull request diff
+func WriteGitHubAnnotations(w io.Writer, findings []secrets.Finding, info Info) error {
+	for _, f := range findings {
+		level := githubLevels[f.Severity]
+		if level == "" {
+			level = "warning"
+		}
+
+		properties := []string{
+			"file=" + githubPropertyEscaper.Replace(info.relPath(f.File)),
+			fmt.Sprintf("line=%d", f.LineNum),
+		}
+		if f.EndLine > f.LineNum {
+			properties = append(properties, fmt.Sprintf("endLine=%d", f.EndLine))
+		} else if f.Column > 0 {
+			properties = append(properties, fmt.Sprintf("col=%d", f.Column))
+		}
+		properties = append(properties, "title="+githubPropertyEscaper.Replace(fmt.Sprintf("%s (%s)", f.Rule, f.Severity)))
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(properties, ","), githubDataEscaper.Replace(message(f))); err != nil {
+			return err
+		}
+	}
+
+	// Paths that could not be scanned are annotated on the file itself
+	for _, e := range info.Stats.Errors {
+		if _, err := fmt.Fprintf(w, "::error file=%s,title=Scan error::%s\n",
+			githubPropertyEscaper.Replace(info.relPath(e.Path)), githubDataEscaper.Replace(errorMessage(e))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteGitHubSummary writes a Markdown summary of findings for the job
+// summary page ($GITHUB_STEP_SUMMARY)
+func WriteGitHubSummary(w io.Writer, findings []secrets.Finding, info Info) error {
+	var b strings.Builder
+	b.WriteString("## Pipeline Guardian secret scan\n\n")
+
+	if len(findings) == 0 {
+		b.WriteString("✅ No security issues found.\n")
+	} else {
+		fmt.Fprintf(&b, "🔴 Found %d potential security issues.\n\n", len(findings))
+		b.WriteString("| Severity | Rule | File | Line |\n")
+		b.WriteString("|----------|------|------|------|\n")
+		for _, f := range findings {
+			fmt.Fprintf(&b, "| %s | %s | `%s` | %d |\n", f.Severity, markdownEscape(f.Rule), markdownEscape(info.relPath(f.File)), f.LineNum)
+		}
+	}
+
+	if len(info.Stats.Errors) > 0 {
+		fmt.Fprintf(&b, "\n⚠️ %d paths could not be scanned:\n\n", len(info.Stats.Errors))
+		for _, e := range info.Stats.Errors {
+			fmt.Fprintf(&b, "- `%s`: %s\n", markdownEscape(info.relPath(e.Path)), errorMessage(e))
+		}
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscape escapes characters that would break a Markdown table cell
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,138 @@
+package report
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	var buf bytes.Buffer
+	info := Info{Root: "/repo", Stats: secrets.Stats{Errors: testScanErrors}}
+	if err := WriteGitHubAnnotations(&buf, testFindings, info); err != nil {
+		t.Fatalf("WriteGitHubAnnotations failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(testFindings)+1 {
+		t.Fatalf("Expected %d workflow commands, got %d", len(testFindings)+1, len(lines))
+	}
+
+	expected := []string{
+		"::notice file=config/deploy.yml,line=22,col=1,title=AWS Access Key (low)::AWS Access Key detected",
+		"::error file=config/deploy.yml,line=22,col=1,title=AWS Credential Pair (critical)::AWS Credential Pair detected",
+		"::error file=keys/sa.json,line=1,endLine=12,title=GCP Service Account Key (critical)::GCP Service Account Key detected in deployer@example.iam.gserviceaccount.com",
+		"::error file=private,title=Scan error::Could not list: permission denied",
+	}
+	for i, want := range expected {
+		if lines[i] != want {
+			t.Errorf("Command %d:\nexpected %s\ngot      %s", i, want, lines[i])
+		}
+	}
+}
+
+func TestWriteGitHubAnnotationsSubdirectory(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "services", "api"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	// scan --path services/api run from the repository root, with one
+	// finding reported by absolute path
+	t.Chdir(repo)
+	findings := []secrets.Finding{
+		{File: "services/api/deploy.yml", Rule: "Generic Secret", Severity: secrets.SeverityHigh, LineNum: 4},
+		{File: filepath.Join(repo, "services", "api", ".env"), Rule: "Generic Secret", Severity: secrets.SeverityHigh, LineNum: 2},
+	}
+	listErr := secrets.ScanError{
+		Path: "services/api/private",
+		Op:   secrets.OpList,
+		Err:  &fs.PathError{Op: "open", Path: "services/api/private", Err: fs.ErrPermission},
+	}
+	info := Info{Root: repo, Stats: secrets.Stats{Errors: []secrets.ScanError{listErr}}}
+
+	var buf bytes.Buffer
+	if err := WriteGitHubAnnotations(&buf, findings, info); err != nil {
+		t.Fatalf("WriteGitHubAnnotations failed: %v", err)
+	}
+
+	// Paths are relative to the repository so annotations land on the diff
+	want := "::error file=services/api/deploy.yml,line=4,title=Generic Secret (high)::Generic Secret detected\n" +
+		"::error file=services/api/.env,line=2,title=Generic Secret (high)::Generic Secret detected\n" +
+		"::error file=services/api/private,title=Scan error::Could not list: permission denied\n"
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestWriteGitHubAnnotationsSingleFile(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "config"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	// scan config/deploy.env run from the repository root
+	t.Chdir(repo)
+	findings := []secrets.Finding{{File: "config/deploy.env", Rule: "Generic Secret", Severity: secrets.SeverityHigh, LineNum: 6}}
+
+	for root, file := range map[string]string{
+		repo: "config/deploy.env",
+		filepath.Join(repo, "config", "deploy.env"): "deploy.env", // The file itself is the root
+	} {
+		var buf bytes.Buffer
+		if err := WriteGitHubAnnotations(&buf, findings, Info{Root: root}); err != nil {
+			t.Fatalf("WriteGitHubAnnotations failed: %v", err)
+		}
+		want := "::error file=" + file + ",line=6,title=Generic Secret (high)::Generic Secret detected\n"
+		if buf.String() != want {
+			t.Errorf("Root %s: expected %q, got %q", root, want, buf.String())
+		}
+	}
+}
+
+func TestWriteGitHubAnnotationsEscaping(t *testing.T) {
+	findings := []secrets.Finding{{
+		File:     "/repo/a,b:c.yml",
+		Rule:     "Generic Secret",
+		Severity: secrets.SeverityMedium,
+		LineNum:  3,
+		Key:      "100%\nsure",
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteGitHubAnnotations(&buf, findings, Info{Root: "/repo"}); err != nil {
+		t.Fatalf("WriteGitHubAnnotations failed: %v", err)
+	}
+
+	want := "::warning file=a%2Cb%3Ac.yml,line=3,title=Generic Secret (medium)::Generic Secret detected in 100%25%0Asure\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestWriteGitHubSummary(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGitHubSummary(&buf, testFindings, Info{Root: "/repo"}); err != nil {
+		t.Fatalf("WriteGitHubSummary failed: %v", err)
+	}
+	summary := buf.String()
+	if !strings.Contains(summary, "Found 3 potential security issues") {
+		t.Errorf("Expected a finding count in the summary:\n%s", summary)
+	}
+	if !strings.Contains(summary, "| critical | GCP Service Account Key | `keys/sa.json` | 1 |") {
+		t.Errorf("Expected a table row per finding:\n%s", summary)
+	}
+
+	buf.Reset()
+	if err := WriteGitHubSummary(&buf, nil, Info{}); err != nil {
+		t.Fatalf("WriteGitHubSummary failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No security issues found") {
+		t.Errorf("Expected a clean summary, got:\n%s", buf.String())
+	}
+}
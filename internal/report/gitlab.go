@@ -0,0 +1,252 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// gitlabSchemaVersion is the version of GitLab's secret detection report
+// schema the report conforms to
+const gitlabSchemaVersion = "15.0.7"
+
+// gitlabTimeFormat is the timestamp format required by GitLab's schemas,
+// which do not allow a time zone
+const gitlabTimeFormat = "2006-01-02T15:04:05"
+
+// gitlabSeverities maps severities onto GitLab vulnerability severities
+var gitlabSeverities = map[secrets.Severity]string{
+	secrets.SeverityLow:      "Low",
+	secrets.SeverityMedium:   "Medium",
+	secrets.SeverityHigh:     "High",
+	secrets.SeverityCritical: "Critical",
+}
+
+// codeClimateSeverities maps severities onto Code Climate issue severities
+var codeClimateSeverities = map[secrets.Severity]string{
+	secrets.SeverityLow:      "minor",
+	secrets.SeverityMedium:   "major",
+	secrets.SeverityHigh:     "critical",
+	secrets.SeverityCritical: "blocker",
+}
+
+type gitlabReport struct {
+	Version         string                `json:"version"`
+	Vulnerabilities []gitlabVulnerability `json:"vulnerabilities"`
+	Scan            gitlabScan            `json:"scan"`
+}
+
+type gitlabVulnerability struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Severity    string             `json:"severity"`
+	Identifiers []gitlabIdentifier `json:"identifiers"`
+	Location    gitlabLocation     `json:"location"`
+}
+
+type gitlabIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type gitlabLocation struct {
+	File      string       `json:"file"`
+	StartLine int          `json:"start_line"`
+	EndLine   int          `json:"end_line,omitempty"`
+	Commit    gitlabCommit `json:"commit"`
+}
+
+type gitlabCommit struct {
+	SHA string `json:"sha"`
+}
+
+type gitlabScan struct {
+	Analyzer  gitlabScanner   `json:"analyzer"`
+	Scanner   gitlabScanner   `json:"scanner"`
+	Type      string          `json:"type"`
+	StartTime string          `json:"start_time"`
+	EndTime   string          `json:"end_time"`
+	Status    string          `json:"status"`
+	Messages  []gitlabMessage `json:"messages,omitempty"`
+}
+
+type gitlabMessage struct {
+	Level string `json:"level"`
+	Value string `json:"value"`
+}
+
+type gitlabScanner struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Version string       `json:"version"`
+	Vendor  gitlabVendor `json:"vendor"`
+}
+
+type gitlabVendor struct {
+	Name string `json:"name"`
+}
+
+// WriteGitLabSecretDetection writes findings as a GitLab secret detection
+// report (gl-secret-detection-report.json) for the merge request security
+// widget. Vulnerability IDs are derived from finding fingerprints so that
+// they are stable between pipelines.
+func WriteGitLabSecretDetection(w io.Writer, findings []secrets.Finding, info Info) error {
+	commit := info.Commit
+	if commit == "" {
+		commit = "0000000"
+	}
+
+	vulnerabilities := []gitlabVulnerability{}
+	for _, f := range findings {
+		severity := gitlabSeverities[f.Severity]
+		if severity == "" {
+			severity = "Unknown"
+		}
+
+		vulnerabilities = append(vulnerabilities, gitlabVulnerability{
+			ID:          fingerprintUUID(info.fingerprint(f)),
+			Name:        f.Rule,
+			Description: message(f),
+			Severity:    severity,
+			Identifiers: []gitlabIdentifier{{
+				Type:  "pipeline_guardian_rule_id",
+				Name:  f.Rule,
+				Value: secrets.RuleID(f.Rule),
+			}},
+			Location: gitlabLocation{
+				File:      info.relPath(f.File),
+				StartLine: f.LineNum,
+				EndLine:   max(f.LineNum, f.EndLine),
+				Commit:    gitlabCommit{SHA: commit},
+			},
+		})
+	}
+
+	scanner := gitlabScanner{
+		ID:      ToolName,
+		Name:    "Pipeline Guardian",
+		Version: info.ToolVersion,
+		Vendor:  gitlabVendor{Name: "Pipeline Guardian"},
+	}
+	if scanner.Version == "" {
+		scanner.Version = "unknown"
+	}
+
+	// Paths that could not be scanned are shown as scan warnings
+	var messages []gitlabMessage
+	for _, e := range info.Stats.Errors {
+		messages = append(messages, gitlabMessage{
+			Level: "warn",
+			Value: info.relPath(e.Path) + ": " + errorMessage(e),
+		})
+	}
+
+	return writeJSON(w, gitlabReport{
+		Version:         gitlabSchemaVersion,
+		Vulnerabilities: vulnerabilities,
+		Scan: gitlabScan{
+			Analyzer:  scanner,
+			Scanner:   scanner,
+			Type:      "secret_detection",
+			StartTime: gitlabTime(info.Started),
+			EndTime:   gitlabTime(info.Finished),
+			Status:    "success",
+			Messages:  messages,
+		},
+	})
+}
+
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    codeClimateLocation `json:"location"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+// WriteCodeClimate writes findings as a Code Climate JSON report, the format
+// read by GitLab's Code Quality widget
+func WriteCodeClimate(w io.Writer, findings []secrets.Finding, info Info) error {
+	issues := []codeClimateIssue{}
+	for _, f := range findings {
+		severity := codeClimateSeverities[f.Severity]
+		if severity == "" {
+			severity = "major"
+		}
+
+		issues = append(issues, codeClimateIssue{
+			Type:        "issue",
+			CheckName:   secrets.RuleID(f.Rule),
+			Description: message(f),
+			Categories:  []string{"Security"},
+			Severity:    severity,
+			Fingerprint: info.fingerprint(f),
+			Location: codeClimateLocation{
+				Path:  info.relPath(f.File),
+				Lines: codeClimateLines{Begin: f.LineNum, End: max(f.LineNum, f.EndLine)},
+			},
+		})
+	}
+
+	// Paths that could not be scanned are reported so the gap is visible
+	for _, e := range info.Stats.Errors {
+		path := info.relPath(e.Path)
+		sum := sha256.Sum256([]byte("scan-error\x00" + e.Op + "\x00" + path))
+		issues = append(issues, codeClimateIssue{
+			Type:        "issue",
+			CheckName:   "scan-error",
+			Description: errorMessage(e),
+			Categories:  []string{"Bug Risk"},
+			Severity:    "info",
+			Fingerprint: hex.EncodeToString(sum[:]),
+			Location: codeClimateLocation{
+				Path:  path,
+				Lines: codeClimateLines{Begin: 1, End: 1},
+			},
+		})
+	}
+
+	return writeJSON(w, issues)
+}
+
+// fingerprintUUID formats the start of a hex fingerprint as a UUID
+func fingerprintUUID(fingerprint string) string {
+	for len(fingerprint) < 32 {
+		fingerprint += "0"
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s", fingerprint[0:8], fingerprint[8:12], fingerprint[12:16], fingerprint[16:20], fingerprint[20:32])
+}
+
+// gitlabTime formats a timestamp for GitLab, defaulting to now
+func gitlabTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format(gitlabTimeFormat)
+}
+
+// writeJSON writes an indented JSON document
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,147 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// gitlabSchemaFile is GitLab's secret detection report schema, at the
+// version the report declares. It is the dist file of
+// gitlab-org/security-report-schemas, vendored unchanged by go generate.
+const gitlabSchemaFile = "testdata/gitlab-secret-detection-report-format-" + gitlabSchemaVersion + ".json"
+
+//go:generate curl -fsSL -o testdata/gitlab-secret-detection-report-format-15.0.7.json https://gitlab.com/gitlab-org/security-products/security-report-schemas/-/raw/v15.0.7/dist/secret-detection-report-format.json
+
+// validateGitLabReport checks a report against gitlabSchemaFile. Until the
+// schema is vendored the check is left out rather than made against a copy
+// that may differ from GitLab's.
+func validateGitLabReport(t *testing.T, report []byte) {
+	t.Helper()
+
+	if _, err := os.Stat(gitlabSchemaFile); errors.Is(err, fs.ErrNotExist) {
+		t.Logf("Not validating against %s; run go generate ./internal/report to vendor it", gitlabSchemaFile)
+		return
+	}
+	validateJSON(t, gitlabSchemaFile, report)
+}
+
+func TestWriteGitLabSecretDetection(t *testing.T) {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	info := Info{
+		ToolVersion: "1.0.0",
+		Root:        "/repo",
+		Commit:      "0123456789abcdef",
+		Started:     started,
+		Finished:    started.Add(3 * time.Second),
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGitLabSecretDetection(&buf, testFindings, info); err != nil {
+		t.Fatalf("WriteGitLabSecretDetection failed: %v", err)
+	}
+	validateGitLabReport(t, buf.Bytes())
+
+	var report gitlabReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+
+	if report.Version != gitlabSchemaVersion {
+		t.Errorf("Expected schema version %s, got %s", gitlabSchemaVersion, report.Version)
+	}
+	scan := report.Scan
+	if scan.Type != "secret_detection" || scan.Status != "success" {
+		t.Errorf("Unexpected scan type/status: %s/%s", scan.Type, scan.Status)
+	}
+	if scan.StartTime != "2024-05-01T12:00:00" || scan.EndTime != "2024-05-01T12:00:03" {
+		t.Errorf("Unexpected scan times: %s - %s", scan.StartTime, scan.EndTime)
+	}
+	if scan.Scanner.ID != ToolName || scan.Scanner.Version != "1.0.0" || scan.Analyzer.Vendor.Name == "" {
+		t.Errorf("Incomplete scanner details: %+v", scan.Scanner)
+	}
+
+	if len(report.Vulnerabilities) != len(testFindings) {
+		t.Fatalf("Expected %d vulnerabilities, got %d", len(testFindings), len(report.Vulnerabilities))
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for _, v := range report.Vulnerabilities {
+		if !uuid.MatchString(v.ID) || seen[v.ID] {
+			t.Errorf("Expected a unique UUID id, got %s", v.ID)
+		}
+		seen[v.ID] = true
+		if len(v.Identifiers) == 0 || v.Location.Commit.SHA != info.Commit {
+			t.Errorf("Incomplete vulnerability: %+v", v)
+		}
+	}
+
+	first := report.Vulnerabilities[0]
+	if first.Severity != "Low" || first.Identifiers[0].Value != "aws-access-key" {
+		t.Errorf("Unexpected first vulnerability: %+v", first)
+	}
+	if first.Location.File != "config/deploy.yml" || first.Location.StartLine != 22 {
+		t.Errorf("Unexpected location: %+v", first.Location)
+	}
+	if location := report.Vulnerabilities[2].Location; location.EndLine != 12 {
+		t.Errorf("Expected the multi-line vulnerability to end on line 12, got %d", location.EndLine)
+	}
+
+	// IDs are stable between runs
+	var again bytes.Buffer
+	if err := WriteGitLabSecretDetection(&again, testFindings, info); err != nil {
+		t.Fatalf("WriteGitLabSecretDetection failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Errorf("Expected identical reports for identical findings")
+	}
+
+	// A clean scan with unreadable paths is still a valid report
+	buf.Reset()
+	if err := WriteGitLabSecretDetection(&buf, nil, Info{Root: "/repo", Stats: secrets.Stats{Errors: testScanErrors}}); err != nil {
+		t.Fatalf("WriteGitLabSecretDetection failed: %v", err)
+	}
+	validateGitLabReport(t, buf.Bytes())
+}
+
+func TestWriteCodeClimate(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCodeClimate(&buf, testFindings, Info{Root: "/repo"}); err != nil {
+		t.Fatalf("WriteCodeClimate failed: %v", err)
+	}
+
+	var issues []codeClimateIssue
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if len(issues) != len(testFindings) {
+		t.Fatalf("Expected %d issues, got %d", len(testFindings), len(issues))
+	}
+
+	first := issues[0]
+	if first.CheckName != "aws-access-key" || first.Severity != "minor" || first.Fingerprint != repoFingerprint(testFindings[0]) {
+		t.Errorf("Unexpected first issue: %+v", first)
+	}
+	if first.Location.Path != "config/deploy.yml" || first.Location.Lines.Begin != 22 {
+		t.Errorf("Unexpected location: %+v", first.Location)
+	}
+	if issues[1].Severity != "blocker" {
+		t.Errorf("Expected critical findings to be blockers, got %s", issues[1].Severity)
+	}
+
+	// A clean scan is an empty array, not null
+	buf.Reset()
+	if err := WriteCodeClimate(&buf, nil, Info{}); err != nil {
+		t.Fatalf("WriteCodeClimate failed: %v", err)
+	}
+	if got := bytes.TrimSpace(buf.Bytes()); string(got) != "[]" {
+		t.Errorf("Expected an empty array, got %s", got)
+	}
+}
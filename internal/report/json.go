@@ -0,0 +1,160 @@
+package report
+
+import (
+	"io"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// SchemaVersion is the version of the JSON report format described by
+// docs/report.schema.json. Bump the minor version for backwards-compatible
+// additions and the major version for anything that breaks consumers.
+const SchemaVersion = "1.0.0"
+
+// SchemaURI identifies the published JSON Schema of the JSON report
+const SchemaURI = "https://raw.githubusercontent.com/richiekrich/pipeline-guardian/main/docs/report.schema.json"
+
+type jsonReport struct {
+	Schema        string         `json:"$schema"`
+	SchemaVersion string         `json:"schema_version"`
+	Tool          jsonTool       `json:"tool"`
+	StartTime     time.Time      `json:"start_time"`
+	EndTime       time.Time      `json:"end_time"`
+	Root          string         `json:"root"`
+	Commit        string         `json:"commit,omitempty"`
+	Rules         []secrets.Rule `json:"rules"`
+	Files         jsonFiles      `json:"files"`
+	Errors        []jsonError    `json:"errors"`
+	DurationMs    float64        `json:"duration_ms"`
+	RuleTimeMs    jsonRuleTime   `json:"rule_time_ms"`
+	Summary       jsonSummary    `json:"summary"`
+	Findings      []jsonFinding  `json:"findings"`
+}
+
+// jsonRuleTime holds the CPU time spent in each pattern and detector in
+// milliseconds
+type jsonRuleTime map[string]float64
+
+type jsonSummary struct {
+	Total      int                      `json:"total"`
+	BySeverity map[secrets.Severity]int `json:"by_severity"`
+	ByRule     map[string]int           `json:"by_rule"`
+	ByFile     map[string]int           `json:"by_file"`
+}
+
+type jsonTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	URI     string `json:"uri"`
+}
+
+type jsonFiles struct {
+	Visited int            `json:"visited"`
+	Scanned int            `json:"scanned"`
+	Bytes   int64          `json:"bytes"`
+	Skipped map[string]int `json:"skipped"`
+}
+
+type jsonError struct {
+	Path  string `json:"path"`
+	Op    string `json:"op"`
+	Error string `json:"error"`
+}
+
+type jsonFinding struct {
+	secrets.Finding
+	RuleID      string `json:"rule_id"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// WriteJSON writes findings in a versioned envelope together with the scan
+// metadata consumers need to tell whether the scan covered the repository
+func WriteJSON(w io.Writer, findings []secrets.Finding, info Info) error {
+	report := jsonReport{
+		Schema:        SchemaURI,
+		SchemaVersion: SchemaVersion,
+		Tool:          jsonTool{Name: ToolName, Version: info.ToolVersion, URI: ToolURI},
+		StartTime:     info.Started,
+		EndTime:       info.Finished,
+		Root:          info.Root,
+		Commit:        info.Commit,
+		Rules:         secrets.Rules(),
+		Files:         newJSONFiles(info.Stats),
+		Errors:        newJSONErrors(info),
+		DurationMs:    milliseconds(info.Stats.Duration),
+		RuleTimeMs:    newJSONRuleTime(info.Stats),
+		Summary:       newJSONSummary(Summarize(findings), info),
+		Findings:      []jsonFinding{},
+	}
+	for _, f := range findings {
+		report.Findings = append(report.Findings, newJSONFinding(f, info))
+	}
+
+	return writeJSON(w, report)
+}
+
+// newJSONFinding adds the rule ID and fingerprint to a finding and makes its
+// paths relative to the scan root
+func newJSONFinding(f secrets.Finding, info Info) jsonFinding {
+	fingerprint := info.fingerprint(f)
+	f.File = info.relPath(f.File)
+	if f.Related != nil {
+		related := make([]secrets.Location, len(f.Related))
+		for i, loc := range f.Related {
+			loc.File = info.relPath(loc.File)
+			related[i] = loc
+		}
+		f.Related = related
+	}
+	if f.Offset == nil {
+		f.Offset = []int{}
+	}
+	return jsonFinding{Finding: f, RuleID: secrets.RuleID(f.Rule), Fingerprint: fingerprint}
+}
+
+// newJSONFiles returns the file counts of a scan
+func newJSONFiles(stats secrets.Stats) jsonFiles {
+	files := jsonFiles{Visited: stats.FilesVisited, Scanned: stats.FilesScanned, Bytes: stats.BytesScanned, Skipped: stats.Skipped}
+	if files.Skipped == nil {
+		files.Skipped = map[string]int{}
+	}
+	return files
+}
+
+// newJSONErrors returns the paths that could not be scanned
+func newJSONErrors(info Info) []jsonError {
+	errs := []jsonError{}
+	for _, e := range info.Stats.Errors {
+		errs = append(errs, jsonError{Path: info.relPath(e.Path), Op: e.Op, Error: e.Unwrap().Error()})
+	}
+	return errs
+}
+
+// newJSONRuleTime returns the time spent per pattern and detector
+func newJSONRuleTime(stats secrets.Stats) jsonRuleTime {
+	ruleTime := jsonRuleTime{}
+	for name, d := range stats.RuleTime {
+		ruleTime[name] = milliseconds(d)
+	}
+	return ruleTime
+}
+
+// newJSONSummary returns finding counts with files relative to the root
+func newJSONSummary(summary Summary, info Info) jsonSummary {
+	byFile := make(map[string]int)
+	for file, n := range summary.ByFile {
+		byFile[info.relPath(file)] += n
+	}
+	return jsonSummary{
+		Total:      summary.Total,
+		BySeverity: summary.BySeverity,
+		ByRule:     summary.ByRule,
+		ByFile:     byFile,
+	}
+}
+
+// milliseconds converts a duration into fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+func TestWriteJSON(t *testing.T) {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	info := Info{
+		ToolVersion: "1.0.0",
+		Root:        "/repo",
+		Started:     started,
+		Finished:    started.Add(time.Second),
+		Stats: secrets.Stats{
+			FilesScanned: 12,
+			BytesScanned: 4096,
+			Skipped:      map[string]int{secrets.SkipIgnored: 3, secrets.SkipBinary: 1},
+			Errors: []secrets.ScanError{{
+				Path: "/repo/private",
+				Op:   secrets.OpList,
+				Err:  &fs.PathError{Op: "open", Path: "/repo/private", Err: fs.ErrPermission},
+			}},
+			FilesVisited: 16,
+			Duration:     1500 * time.Millisecond,
+			RuleTime:     map[string]time.Duration{"AWS Access Key": 2500 * time.Microsecond},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, testFindings, info); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	validateJSON(t, "../../docs/report.schema.json", buf.Bytes())
+
+	var report map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report["schema_version"] != SchemaVersion {
+		t.Errorf("Expected schema version %s, got %v", SchemaVersion, report["schema_version"])
+	}
+	if report["start_time"] != "2024-05-01T12:00:00Z" {
+		t.Errorf("Unexpected start time %v", report["start_time"])
+	}
+	files := report["files"].(map[string]interface{})
+	if files["scanned"] != float64(12) || files["skipped"].(map[string]interface{})["ignored"] != float64(3) {
+		t.Errorf("Unexpected file counts: %v", files)
+	}
+	if files["visited"] != float64(16) {
+		t.Errorf("Expected 16 visited files, got %v", files["visited"])
+	}
+	if report["duration_ms"] != float64(1500) || report["rule_time_ms"].(map[string]interface{})["AWS Access Key"] != 2.5 {
+		t.Errorf("Unexpected timings: %v %v", report["duration_ms"], report["rule_time_ms"])
+	}
+	scanErrors := report["errors"].([]interface{})
+	if len(scanErrors) != 1 {
+		t.Fatalf("Expected 1 error, got %v", scanErrors)
+	}
+	if e := scanErrors[0].(map[string]interface{}); e["path"] != "private" || e["op"] != "list" || e["error"] != "permission denied" {
+		t.Errorf("Unexpected error: %v", e)
+	}
+	summary := report["summary"].(map[string]interface{})
+	if summary["total"] != float64(3) || summary["by_file"].(map[string]interface{})["config/deploy.yml"] != float64(2) {
+		t.Errorf("Unexpected summary: %v", summary)
+	}
+	if errs := report["errors"].([]interface{}); len(errs) != 1 {
+		t.Errorf("Expected 1 error, got %v", errs)
+	}
+	if len(report["rules"].([]interface{})) != len(secrets.Rules()) {
+		t.Errorf("Expected every rule to be listed")
+	}
+
+	first := report["findings"].([]interface{})[0].(map[string]interface{})
+	for _, field := range []string{"line", "line_text", "rule_id", "fingerprint"} {
+		if _, ok := first[field]; !ok {
+			t.Errorf("Expected snake_case field %s in findings, got %v", field, first)
+		}
+	}
+	if _, ok := first["LineNum"]; ok {
+		t.Errorf("Unexpected Go-cased field in findings")
+	}
+	if first["fingerprint"] != repoFingerprint(testFindings[0]) {
+		t.Errorf("Expected the finding fingerprint")
+	}
+
+	// A clean scan still has all the metadata and empty lists
+	buf.Reset()
+	if err := WriteJSON(&buf, nil, Info{Root: "/repo"}); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	validateJSON(t, "../../docs/report.schema.json", buf.Bytes())
+}
+
+func TestWriteJSONRepositoryPaths(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "config"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	// A scan run from a subdirectory finds files relative to it
+	t.Chdir(filepath.Join(repo, "config"))
+	f := testFindings[0]
+	f.File = "deploy.yml"
+	f.Related = []secrets.Location{{File: "../.env", Rule: "AWS Secret Key", LineNum: 3}}
+
+	check := func(format string, finding map[string]interface{}) {
+		t.Helper()
+		if finding["file"] != "config/deploy.yml" {
+			t.Errorf("%s: expected a path relative to the repository, got %v", format, finding["file"])
+		}
+		related := finding["related"].([]interface{})[0].(map[string]interface{})
+		if related["file"] != ".env" {
+			t.Errorf("%s: expected a related path relative to the repository, got %v", format, related["file"])
+		}
+		if finding["fingerprint"] != repoFingerprint(testFindings[0]) {
+			t.Errorf("%s: expected the fingerprint of config/deploy.yml, got %v", format, finding["fingerprint"])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, []secrets.Finding{f}, Info{Root: repo}); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var report struct {
+		Findings []map[string]interface{} `json:"findings"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	check("json", report.Findings[0])
+
+	buf.Reset()
+	if err := NewNDJSONWriter(&buf, Info{Root: repo}).WriteFinding(f); err != nil {
+		t.Fatalf("WriteFinding failed: %v", err)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+	check("ndjson", record)
+}
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// NDJSON record types
+const (
+	RecordFinding = "finding" // One per finding, as soon as it is found
+	RecordSummary = "summary" // Always the last record
+)
+
+type ndjsonFinding struct {
+	Type string `json:"type"`
+	jsonFinding
+}
+
+type ndjsonSummary struct {
+	Type          string                   `json:"type"`
+	SchemaVersion string                   `json:"schema_version"`
+	Tool          jsonTool                 `json:"tool"`
+	StartTime     time.Time                `json:"start_time"`
+	EndTime       time.Time                `json:"end_time"`
+	Root          string                   `json:"root"`
+	Commit        string                   `json:"commit,omitempty"`
+	Files         jsonFiles                `json:"files"`
+	Errors        []jsonError              `json:"errors"`
+	DurationMs    float64                  `json:"duration_ms"`
+	RuleTimeMs    jsonRuleTime             `json:"rule_time_ms"`
+	Findings      int                      `json:"findings"`
+	BySeverity    map[secrets.Severity]int `json:"by_severity"`
+	ByRule        map[string]int           `json:"by_rule"`
+	ByFile        map[string]int           `json:"by_file"`
+}
+
+// NDJSONWriter writes findings as newline-delimited JSON while a scan is
+// still running. Each line is a finding record in the same shape as the
+// findings of the JSON report, and the stream ends with a summary record.
+type NDJSONWriter struct {
+	encoder *json.Encoder
+	info    Info
+	summary Summary
+}
+
+// NewNDJSONWriter starts an NDJSON stream on w for a scan described by info,
+// whose Root fingerprints are computed against
+func NewNDJSONWriter(w io.Writer, info Info) *NDJSONWriter {
+	return &NDJSONWriter{
+		encoder: json.NewEncoder(w),
+		info:    info,
+		summary: Summarize(nil),
+	}
+}
+
+// WriteFinding writes a finding record
+func (n *NDJSONWriter) WriteFinding(f secrets.Finding) error {
+	n.summary.add(f)
+
+	return n.encoder.Encode(ndjsonFinding{Type: RecordFinding, jsonFinding: newJSONFinding(f, n.info)})
+}
+
+// WriteSummary ends the stream with a summary of the scan and of the
+// findings written so far
+func (n *NDJSONWriter) WriteSummary(info Info) error {
+	summary := ndjsonSummary{
+		Type:          RecordSummary,
+		SchemaVersion: SchemaVersion,
+		Tool:          jsonTool{Name: ToolName, Version: info.ToolVersion, URI: ToolURI},
+		StartTime:     info.Started,
+		EndTime:       info.Finished,
+		Root:          info.Root,
+		Commit:        info.Commit,
+		Files:         newJSONFiles(info.Stats),
+		Errors:        newJSONErrors(info),
+		DurationMs:    milliseconds(info.Stats.Duration),
+		RuleTimeMs:    newJSONRuleTime(info.Stats),
+	}
+	counts := newJSONSummary(n.summary, info)
+	summary.Findings, summary.BySeverity, summary.ByRule, summary.ByFile = counts.Total, counts.BySeverity, counts.ByRule, counts.ByFile
+	return n.encoder.Encode(summary)
+}
+
+// WriteNDJSON writes already collected findings as an NDJSON stream
+func WriteNDJSON(w io.Writer, findings []secrets.Finding, info Info) error {
+	stream := NewNDJSONWriter(w, info)
+	for _, f := range findings {
+		if err := stream.WriteFinding(f); err != nil {
+			return err
+		}
+	}
+	return stream.WriteSummary(info)
+}
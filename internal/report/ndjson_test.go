@@ -0,0 +1,61 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	stream := NewNDJSONWriter(&buf, Info{Root: "/repo"})
+
+	// Each finding is written as soon as it is passed in
+	if err := stream.WriteFinding(testFindings[0]); err != nil {
+		t.Fatalf("WriteFinding failed: %v", err)
+	}
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Fatalf("Expected the finding to be written immediately, got %q", buf.String())
+	}
+	for _, f := range testFindings[1:] {
+		if err := stream.WriteFinding(f); err != nil {
+			t.Fatalf("WriteFinding failed: %v", err)
+		}
+	}
+	info := Info{Root: "/repo", Stats: secrets.Stats{FilesScanned: 5}}
+	if err := stream.WriteSummary(info); err != nil {
+		t.Fatalf("WriteSummary failed: %v", err)
+	}
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Line is not a JSON object: %s", scanner.Text())
+		}
+		records = append(records, record)
+	}
+
+	if len(records) != len(testFindings)+1 {
+		t.Fatalf("Expected %d records, got %d", len(testFindings)+1, len(records))
+	}
+	first := records[0]
+	if first["type"] != RecordFinding || first["rule_id"] != "aws-access-key" || first["line"] != float64(22) {
+		t.Errorf("Unexpected finding record: %v", first)
+	}
+
+	summary := records[len(records)-1]
+	if summary["type"] != RecordSummary || summary["findings"] != float64(3) {
+		t.Errorf("Unexpected summary record: %v", summary)
+	}
+	if summary["by_severity"].(map[string]interface{})["critical"] != float64(2) {
+		t.Errorf("Expected 2 critical findings in the summary, got %v", summary["by_severity"])
+	}
+	if summary["files"].(map[string]interface{})["scanned"] != float64(5) {
+		t.Errorf("Expected the file counts in the summary, got %v", summary["files"])
+	}
+}
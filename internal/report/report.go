@@ -0,0 +1,92 @@
+// Package report renders scan findings in the formats consumed by CI/CD
+// platforms and code-scanning tools.
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// ToolName is the name reports use for the scanner
+const ToolName = "pipeline-guardian"
+
+// ToolURI is the project home page referenced by reports
+const ToolURI = "https://github.com/richiekrich/pipeline-guardian"
+
+// Info describes the scan that produced a set of findings
+type Info struct {
+	ToolVersion string        // Version of pipeline-guardian
+	Root        string        // Repository root; file paths are reported relative to it
+	Commit      string        // Commit that was scanned, if known
+	Started     time.Time     // When the scan started
+	Finished    time.Time     // When the scan finished
+	Stats       secrets.Stats // Coverage of the scan
+}
+
+// relPath returns a finding's file relative to the repository root with
+// forward slashes, as expected by code-scanning tools. Paths relative to the
+// current directory are resolved first, so that a scan of a subdirectory
+// still reports paths from the top of the repository. Paths outside the
+// root are returned as given.
+func (i Info) relPath(path string) string {
+	if i.Root != "" {
+		target := path
+		if filepath.IsAbs(i.Root) && !filepath.IsAbs(target) {
+			if abs, err := filepath.Abs(target); err == nil {
+				target = abs
+			}
+		}
+		if rel, ok := within(i.Root, target); ok {
+			path = rel
+		} else if resolved, err := filepath.EvalSymlinks(target); err == nil {
+			// The root reported by git has its symlinks resolved
+			if rel, ok := within(i.Root, resolved); ok {
+				path = rel
+			}
+		}
+		if path == "." {
+			// The root is the scanned file itself
+			path = filepath.Base(target)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// within returns path relative to root, and whether it is inside root
+func within(root, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// fingerprint returns the fingerprint of a finding computed from its path
+// relative to the repository root, so that it is the same in every checkout
+// and on every machine
+func (i Info) fingerprint(f secrets.Finding) string {
+	f.File = i.relPath(f.File)
+	return f.Fingerprint()
+}
+
+// message returns a one-line description of a finding that never includes
+// the secret itself
+func message(f secrets.Finding) string {
+	msg := f.Rule + " detected"
+	if f.Key != "" {
+		msg += " in " + f.Key
+	}
+	if f.Verification != "" {
+		msg += " (" + f.Verification + ")"
+	}
+	return msg
+}
+
+// errorMessage describes why a path could not be scanned, without the path
+func errorMessage(e secrets.ScanError) string {
+	return fmt.Sprintf("Could not %s: %v", e.Op, e.Unwrap())
+}
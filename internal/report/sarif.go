@@ -0,0 +1,258 @@
+package report
+
+import (
+	"io"
+	"sort"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// sarifSchema is the published location of the SARIF 2.1.0 JSON schema
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifFingerprint is the partialFingerprints key of a finding's fingerprint
+const sarifFingerprint = "secretFingerprint/v1"
+
+// sarifLevels maps severities onto SARIF result levels
+var sarifLevels = map[secrets.Severity]string{
+	secrets.SeverityLow:      "note",
+	secrets.SeverityMedium:   "warning",
+	secrets.SeverityHigh:     "error",
+	secrets.SeverityCritical: "error",
+}
+
+// securitySeverities maps severities onto the CVSS-like scores GitHub code
+// scanning uses to rank security results
+var securitySeverities = map[secrets.Severity]string{
+	secrets.SeverityLow:      "3.0",
+	secrets.SeverityMedium:   "5.5",
+	secrets.SeverityHigh:     "8.0",
+	secrets.SeverityCritical: "9.5",
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string                      `json:"level"`
+	Message   sarifMessage                `json:"message"`
+	Locations []sarifNotificationLocation `json:"locations"`
+}
+
+type sarifNotificationLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration  `json:"defaultConfiguration"`
+	Properties           sarifRuleProperties `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProperties struct {
+	Tags             []string `json:"tags"`
+	SecuritySeverity string   `json:"security-severity"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	RelatedLocations    []sarifLocation    `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification"`
+}
+
+// sarifSuppressions describes how each kind of suppression was made: in the
+// source by a comment, or outside it by a baseline
+var sarifSuppressions = map[string]sarifSuppression{
+	secrets.SuppressionInline:   {Kind: "inSource", Status: "accepted", Justification: "Ignored by a pipeline-guardian:ignore comment"},
+	secrets.SuppressionBaseline: {Kind: "external", Status: "accepted", Justification: "Accepted in the baseline"},
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// WriteSARIF writes findings as a SARIF 2.1.0 log for GitHub code scanning
+// and IDE SARIF viewers. Each rule that produced a finding is described in
+// the tool's rule list, and results carry the finding's fingerprint so that
+// code-scanning tools can track them across commits. Suppressed findings are
+// included with their suppression, so that viewers can show them as
+// dismissed.
+func WriteSARIF(w io.Writer, findings []secrets.Finding, info Info) error {
+	// Describe every rule that produced a finding, in a stable order
+	ruleIndexes := make(map[string]int)
+	var names []string
+	for _, f := range findings {
+		if _, ok := ruleIndexes[f.Rule]; !ok {
+			ruleIndexes[f.Rule] = -1
+			names = append(names, f.Rule)
+		}
+	}
+	sort.Strings(names)
+
+	rules := []sarifRule{}
+	for i, name := range names {
+		ruleIndexes[name] = i
+		severity := secrets.SeverityFor(name)
+		rules = append(rules, sarifRule{
+			ID:                   secrets.RuleID(name),
+			Name:                 name,
+			ShortDescription:     sarifMessage{Text: name + " detected"},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevels[severity]},
+			Properties: sarifRuleProperties{
+				Tags:             []string{"security", "secret"},
+				SecuritySeverity: securitySeverities[severity],
+			},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, f := range findings {
+		result := sarifResult{
+			RuleID:    secrets.RuleID(f.Rule),
+			RuleIndex: ruleIndexes[f.Rule],
+			Level:     sarifLevels[f.Severity],
+			Message:   sarifMessage{Text: message(f)},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: info.relPath(f.File)},
+					Region:           sarifRegionFor(f),
+				},
+			}},
+			PartialFingerprints: map[string]string{
+				sarifFingerprint: info.fingerprint(f),
+			},
+		}
+		if result.Level == "" {
+			result.Level = "warning"
+		}
+		if suppression, ok := sarifSuppressions[f.Suppression]; ok {
+			result.Suppressions = []sarifSuppression{suppression}
+		}
+
+		for i, r := range f.Related {
+			result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+				ID: i + 1,
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: info.relPath(r.File)},
+					Region:           sarifRegion{StartLine: r.LineNum},
+				},
+				Message: &sarifMessage{Text: r.Rule},
+			})
+		}
+
+		results = append(results, result)
+	}
+
+	// Paths that could not be scanned are reported as tool notifications
+	invocation := sarifInvocation{ExecutionSuccessful: true}
+	for _, e := range info.Stats.Errors {
+		var location sarifNotificationLocation
+		location.PhysicalLocation.ArtifactLocation.URI = info.relPath(e.Path)
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+			Level:     "error",
+			Message:   sarifMessage{Text: errorMessage(e)},
+			Locations: []sarifNotificationLocation{location},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           ToolName,
+				Version:        info.ToolVersion,
+				InformationURI: ToolURI,
+				Rules:          rules,
+			}},
+			Invocations: []sarifInvocation{invocation},
+			Results:     results,
+		}},
+	}
+
+	return writeJSON(w, log)
+}
+
+// sarifRegionFor returns the region of a finding. Columns are only reported
+// for matches on a single line.
+func sarifRegionFor(f secrets.Finding) sarifRegion {
+	region := sarifRegion{StartLine: max(f.LineNum, 1)}
+	if f.EndLine > f.LineNum {
+		region.EndLine = f.EndLine
+		return region
+	}
+
+	if f.Column > 0 {
+		region.StartColumn = f.Column
+		if len(f.Offset) == 2 && f.Offset[1] > f.Offset[0] {
+			region.EndColumn = f.Column + f.Offset[1] - f.Offset[0]
+		}
+	}
+	return region
+}
@@ -0,0 +1,227 @@
+package report
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// builtinTemplates holds the example templates shipped with the tool
+//
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// ansiColors holds the terminal colors used for each severity
+var ansiColors = map[secrets.Severity]string{
+	secrets.SeverityLow:      "\033[36m", // Cyan
+	secrets.SeverityMedium:   "\033[33m", // Yellow
+	secrets.SeverityHigh:     "\033[31m", // Red
+	secrets.SeverityCritical: "\033[1;31m",
+}
+
+// ansiReset resets the terminal color
+const ansiReset = "\033[0m"
+
+// TemplateData is the value templates are executed with
+type TemplateData struct {
+	Tool     TemplateTool
+	Root     string
+	Commit   string
+	Started  time.Time
+	Finished time.Time
+	Duration time.Duration
+	Findings []TemplateFinding
+	Summary  Summary
+	Errors   []secrets.ScanError // Paths that could not be scanned
+}
+
+// TemplateFinding is a finding as templates see it. The secret is left out
+// and LineText is redacted, so that no template can print either.
+type TemplateFinding struct {
+	File         string             `json:"file"`
+	Rule         string             `json:"rule"`
+	Severity     secrets.Severity   `json:"severity"`
+	LineNum      int                `json:"line"`
+	EndLine      int                `json:"end_line,omitempty"`
+	Column       int                `json:"column"`
+	LineText     string             `json:"line_text"`
+	Key          string             `json:"key,omitempty"`
+	Related      []secrets.Location `json:"related,omitempty"`
+	Verification string             `json:"verification,omitempty"`
+}
+
+// newTemplateFinding returns the template view of a finding
+func newTemplateFinding(f secrets.Finding) TemplateFinding {
+	return TemplateFinding{
+		File:         f.File,
+		Rule:         f.Rule,
+		Severity:     f.Severity,
+		LineNum:      f.LineNum,
+		EndLine:      f.EndLine,
+		Column:       f.Column,
+		LineText:     redact(f),
+		Key:          f.Key,
+		Related:      f.Related,
+		Verification: f.Verification,
+	}
+}
+
+// TemplateTool describes the scanner to templates
+type TemplateTool struct {
+	Name    string
+	Version string
+	URI     string
+}
+
+// Summary counts findings by severity, by rule and by file
+type Summary struct {
+	Total      int
+	BySeverity map[secrets.Severity]int
+	ByRule     map[string]int
+	ByFile     map[string]int
+	Rules      []string // Rules with findings, sorted by name
+}
+
+// Summarize counts findings by severity, by rule and by file
+func Summarize(findings []secrets.Finding) Summary {
+	summary := Summary{
+		BySeverity: make(map[secrets.Severity]int),
+		ByRule:     make(map[string]int),
+		ByFile:     make(map[string]int),
+	}
+	for _, f := range findings {
+		summary.add(f)
+	}
+	sort.Strings(summary.Rules)
+	return summary
+}
+
+// add counts a finding
+func (s *Summary) add(f secrets.Finding) {
+	s.Total++
+	s.BySeverity[f.Severity]++
+	if s.ByRule[f.Rule] == 0 {
+		s.Rules = append(s.Rules, f.Rule)
+	}
+	s.ByRule[f.Rule]++
+	s.ByFile[f.File]++
+}
+
+// BuiltinTemplates returns the names of the built-in templates
+func BuiltinTemplates() []string {
+	entries, _ := builtinTemplates.ReadDir("templates")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".tmpl"))
+	}
+	return names
+}
+
+// ParseTemplate loads a template from a file or, if no such file exists, one
+// of the built-in templates by name
+func ParseTemplate(nameOrPath string, info Info) (*template.Template, error) {
+	text, err := os.ReadFile(nameOrPath)
+	if os.IsNotExist(err) {
+		text, err = builtinTemplates.ReadFile(path.Join("templates", nameOrPath+".tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("template %q is neither a file nor a built-in template (%s)",
+				nameOrPath, strings.Join(BuiltinTemplates(), ", "))
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return template.New(path.Base(nameOrPath)).Funcs(templateFuncs(info)).Funcs(colorFuncs(false)).Parse(string(text))
+}
+
+// templateFuncs returns the helper functions available to templates
+func templateFuncs(info Info) template.FuncMap {
+	return template.FuncMap{
+		"redact":  func(f TemplateFinding) string { return f.LineText },
+		"relpath": info.relPath,
+		"ruleID":  secrets.RuleID,
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"csv":   csvEscape,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// colorFuncs returns the terminal color helpers, which produce nothing when
+// colors are off
+func colorFuncs(color bool) template.FuncMap {
+	return template.FuncMap{
+		"severityColor": func(severity secrets.Severity) string {
+			if !color {
+				return ""
+			}
+			return ansiColors[severity]
+		},
+		"resetColor": func() string {
+			if !color {
+				return ""
+			}
+			return ansiReset
+		},
+	}
+}
+
+// WriteTemplate renders findings through a template from ParseTemplate, with
+// severityColor and resetColor producing ANSI colors only when color is set
+func WriteTemplate(w io.Writer, tmpl *template.Template, findings []secrets.Finding, info Info, color bool) error {
+	views := make([]TemplateFinding, 0, len(findings))
+	for _, f := range findings {
+		views = append(views, newTemplateFinding(f))
+	}
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	return tmpl.Funcs(colorFuncs(color)).Execute(w, TemplateData{
+		Tool:     TemplateTool{Name: ToolName, Version: info.ToolVersion, URI: ToolURI},
+		Root:     info.Root,
+		Commit:   info.Commit,
+		Started:  info.Started,
+		Finished: info.Finished,
+		Duration: info.Finished.Sub(info.Started),
+		Findings: views,
+		Summary:  Summarize(findings),
+		Errors:   info.Stats.Errors,
+	})
+}
+
+// redact returns a finding's line with its secret, and anything else in the
+// line that matches a secret pattern, masked down to its first characters
+func redact(f secrets.Finding) string {
+	var lineSecrets []string
+	if f.Secret != "" {
+		lineSecrets = append(lineSecrets, f.Secret)
+	}
+	return unmark(redactLine(f.LineText, lineSecrets))
+}
+
+// mask replaces all but the first few characters of a secret with asterisks
+func mask(secret string) string {
+	visible := min(4, len(secret)/4)
+	return secret[:visible] + strings.Repeat("*", len(secret)-visible)
+}
+
+// csvEscape quotes a CSV field when needed
+func csvEscape(s string) string {
+	if !strings.ContainsAny(s, ",\"\r\n") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,396 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// TextOptions configures the terminal text output
+type TextOptions struct {
+	Color   bool // Use ANSI colors
+	Context int  // Lines of context shown around each finding
+}
+
+// Sentinels mark redacted secrets until they are highlighted
+const (
+	redactStart = "\x00"
+	redactEnd   = "\x01"
+)
+
+// redacted matches a redacted secret marked with the sentinels
+var redacted = regexp.MustCompile(redactStart + "([^" + redactEnd + "]*)" + redactEnd)
+
+// textStyle applies ANSI styles when colors are enabled
+type textStyle struct {
+	color bool
+}
+
+// wrap surrounds s with an ANSI sequence and a reset
+func (s textStyle) wrap(code, text string) string {
+	if !s.color || code == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+func (s textStyle) bold(text string) string { return s.wrap("\033[1m", text) }
+func (s textStyle) dim(text string) string  { return s.wrap("\033[2m", text) }
+func (s textStyle) severity(severity secrets.Severity, text string) string {
+	return s.wrap(ansiColors[severity], text)
+}
+func (s textStyle) secret(text string) string { return s.wrap("\033[1;7;31m", text) }
+
+// WriteRichText writes findings for a terminal: grouped by file, with lines
+// of context around each finding, secrets redacted and highlighted, and a
+// per-rule summary table at the end. Source files are read again to show
+// context; files that can no longer be read fall back to the matched line.
+func WriteRichText(w io.Writer, findings []secrets.Finding, info Info, opts TextOptions) error {
+	style := textStyle{color: opts.Color}
+	var b strings.Builder
+
+	if len(findings) == 0 {
+		b.WriteString(style.bold("✅ Scan completed. No security issues found.") + "\n\n")
+		writeScanStats(&b, findings, info, style)
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	// Group findings by file, ordered by path and line
+	sorted := make([]secrets.Finding, len(findings))
+	copy(sorted, findings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].File != sorted[j].File {
+			return sorted[i].File < sorted[j].File
+		}
+		return sorted[i].LineNum < sorted[j].LineNum
+	})
+
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].File == sorted[start].File {
+			end++
+		}
+		writeFileGroup(&b, sorted[start:end], info, opts, style)
+		start = end
+	}
+
+	writeRuleSummary(&b, findings, style)
+	b.WriteString("\n")
+	writeScanStats(&b, findings, info, style)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeFileGroup writes the findings of a single file
+func writeFileGroup(b *strings.Builder, findings []secrets.Finding, info Info, opts TextOptions, style textStyle) {
+	path := info.relPath(findings[0].File)
+	fmt.Fprintf(b, "%s %s\n", style.bold(path), style.dim(fmt.Sprintf("(%d)", len(findings))))
+
+	// Every secret found in the file is redacted wherever it shows up
+	var fileSecrets []string
+	for _, f := range findings {
+		if f.Secret != "" {
+			fileSecrets = append(fileSecrets, f.Secret)
+		}
+	}
+
+	var lines []string
+	if content, err := os.ReadFile(findings[0].File); err == nil {
+		lines = strings.Split(strings.TrimSuffix(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"), "\n")
+	}
+
+	for _, f := range findings {
+		location := fmt.Sprintf("line %d", f.LineNum)
+		if f.EndLine > f.LineNum {
+			location = fmt.Sprintf("lines %d-%d", f.LineNum, f.EndLine)
+		} else if f.Column > 0 {
+			location = fmt.Sprintf("line %d:%d", f.LineNum, f.Column)
+		}
+		fmt.Fprintf(b, "  %s %s %s\n",
+			style.severity(f.Severity, fmt.Sprintf("● %-8s", f.Severity)),
+			style.bold(f.Rule),
+			style.dim(location))
+		if f.Key != "" {
+			fmt.Fprintf(b, "      Key: %s\n", f.Key)
+		}
+
+		writeContext(b, f, lines, fileSecrets, opts.Context, style)
+
+		for _, r := range f.Related {
+			fmt.Fprintf(b, "      %s\n", style.dim(fmt.Sprintf("Related: %s (line %d)", r.Rule, r.LineNum)))
+		}
+		if f.Verification != "" {
+			fmt.Fprintf(b, "      Verification: %s\n", f.Verification)
+		}
+	}
+	b.WriteString("\n")
+}
+
+// writeContext writes the lines around a finding with the finding's line
+// marked. The body of a multi-line secret is elided rather than shown.
+func writeContext(b *strings.Builder, f secrets.Finding, lines []string, fileSecrets []string, context int, style textStyle) {
+	if f.LineNum < 1 || f.LineNum > len(lines) {
+		// The file changed or cannot be read; show what the scan saw
+		fmt.Fprintf(b, "    %s %s\n", style.severity(f.Severity, ">"), highlight(redactLine(f.LineText, fileSecrets), style))
+		return
+	}
+
+	last := max(f.LineNum, f.EndLine)
+	width := len(fmt.Sprint(min(last+context, len(lines))))
+	gutter := func(marker string, n int) string {
+		return fmt.Sprintf("    %s %s %s ", marker, style.dim(fmt.Sprintf("%*d", width, n)), style.dim("│"))
+	}
+
+	for n := max(1, f.LineNum-context); n <= min(len(lines), last+context); n++ {
+		switch {
+		case n == f.LineNum:
+			fmt.Fprintf(b, "%s%s\n", gutter(style.severity(f.Severity, ">"), n), highlight(redactLine(lines[n-1], fileSecrets), style))
+		case n > f.LineNum && n <= last:
+			// Skip the rest of a multi-line secret in one go
+			fmt.Fprintf(b, "    %s %s\n", strings.Repeat(" ", width+2), style.dim(fmt.Sprintf("⋮ %d more lines of the secret", last-f.LineNum)))
+			n = last
+		default:
+			fmt.Fprintf(b, "%s%s\n", gutter(" ", n), highlight(redactLine(lines[n-1], fileSecrets), style))
+		}
+	}
+}
+
+// writeRuleSummary writes a table of findings per rule, most severe first
+func writeRuleSummary(b *strings.Builder, findings []secrets.Finding, style textStyle) {
+	summary := Summarize(findings)
+	severities := make(map[string]secrets.Severity)
+	files := make(map[string]bool)
+	for _, f := range findings {
+		if current, ok := severities[f.Rule]; !ok || !current.AtLeast(f.Severity) {
+			severities[f.Rule] = f.Severity
+		}
+		files[f.File] = true
+	}
+
+	rules := summary.Rules
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := severities[rules[i]], severities[rules[j]]
+		return a != b && a.AtLeast(b)
+	})
+
+	width := len("Rule")
+	for _, rule := range rules {
+		width = max(width, len(rule))
+	}
+
+	b.WriteString(style.bold("Summary") + "\n")
+	fmt.Fprintf(b, "  %-*s  %-8s  %s\n", width, "Rule", "Severity", "Findings")
+	for _, rule := range rules {
+		severity := severities[rule]
+		fmt.Fprintf(b, "  %-*s  %s  %8d\n", width, rule, style.severity(severity, fmt.Sprintf("%-8s", severity)), summary.ByRule[rule])
+	}
+
+	var counts []string
+	for _, severity := range []secrets.Severity{secrets.SeverityCritical, secrets.SeverityHigh, secrets.SeverityMedium, secrets.SeverityLow} {
+		if n := summary.BySeverity[severity]; n > 0 {
+			counts = append(counts, style.severity(severity, fmt.Sprintf("%d %s", n, severity)))
+		}
+	}
+	fmt.Fprintf(b, "\n🔴 %d potential security issues in %d files (%s)\n", summary.Total, len(files), strings.Join(counts, ", "))
+}
+
+// slowestRules is the number of rules listed by their CPU time in the scan
+// summary
+const slowestRules = 5
+
+// WriteScanSummary writes the coverage, finding counts and timing of a scan
+// as plain text
+func WriteScanSummary(w io.Writer, findings []secrets.Finding, info Info) error {
+	var b strings.Builder
+	writeScanStats(&b, findings, info, textStyle{})
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeScanStats writes what a scan covered, how its findings are spread and
+// where its time went. Nothing is written when there are no statistics.
+func writeScanStats(b *strings.Builder, findings []secrets.Finding, info Info, style textStyle) {
+	stats := info.Stats
+	if stats.FilesVisited == 0 && stats.Duration == 0 {
+		return
+	}
+
+	skipped := 0
+	var reasons []string
+	for reason, n := range stats.Skipped {
+		skipped += n
+		reasons = append(reasons, fmt.Sprintf("%s %d", reason, n))
+	}
+	sort.Strings(reasons)
+	files := fmt.Sprintf("%d visited, %d scanned, %d skipped", stats.FilesVisited, stats.FilesScanned, skipped)
+	if len(reasons) > 0 {
+		files += " (" + strings.Join(reasons, ", ") + ")"
+	}
+
+	b.WriteString(style.bold("Scan summary") + "\n")
+	fmt.Fprintf(b, "  Files:     %s\n", files)
+	fmt.Fprintf(b, "  Bytes:     %s\n", formatBytes(stats.BytesScanned))
+	fmt.Fprintf(b, "  Errors:    %d\n", len(stats.Errors))
+	for _, e := range stats.Errors {
+		fmt.Fprintf(b, "    %s: %s\n", info.relPath(e.Path), errorMessage(e))
+	}
+	fmt.Fprintf(b, "  Wall time: %s\n", roundDuration(stats.Duration))
+
+	if len(findings) > 0 {
+		summary := Summarize(findings)
+
+		var severities []string
+		for _, severity := range []secrets.Severity{secrets.SeverityCritical, secrets.SeverityHigh, secrets.SeverityMedium, secrets.SeverityLow} {
+			if n := summary.BySeverity[severity]; n > 0 {
+				severities = append(severities, style.severity(severity, fmt.Sprintf("%d %s", n, severity)))
+			}
+		}
+		fmt.Fprintf(b, "  Findings:  %s\n", strings.Join(severities, ", "))
+		fmt.Fprintf(b, "  By rule:   %s\n", joinCounts(summary.ByRule, nil))
+		fmt.Fprintf(b, "  By file:   %s\n", joinCounts(summary.ByFile, info.relPath))
+	}
+
+	if len(stats.RuleTime) > 0 {
+		names := make([]string, 0, len(stats.RuleTime))
+		for name := range stats.RuleTime {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if stats.RuleTime[names[i]] != stats.RuleTime[names[j]] {
+				return stats.RuleTime[names[i]] > stats.RuleTime[names[j]]
+			}
+			return names[i] < names[j]
+		})
+
+		var slowest []string
+		for _, name := range names[:min(slowestRules, len(names))] {
+			slowest = append(slowest, fmt.Sprintf("%s %s", name, roundDuration(stats.RuleTime[name])))
+		}
+		fmt.Fprintf(b, "  Slowest:   %s\n", strings.Join(slowest, ", "))
+	}
+}
+
+// joinCounts lists counts from most to least, e.g. "a.env 3, b.env 1"
+func joinCounts(counts map[string]int, label func(string) string) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name := key
+		if label != nil {
+			name = label(key)
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", name, counts[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// roundDuration rounds a duration to a precision that suits its size
+func roundDuration(d time.Duration) time.Duration {
+	if d < 10*time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// redactLine masks the given secrets and anything matching a secret pattern
+// in a line, marking each masked secret with the redaction sentinels
+func redactLine(line string, fileSecrets []string) string {
+	for _, secret := range secretLines(fileSecrets) {
+		line = redactTruncated(line, secret)
+		line = strings.ReplaceAll(line, secret, redactStart+mask(secret)+redactEnd)
+	}
+	for _, pattern := range secrets.Patterns {
+		line = pattern.ReplaceAllStringFunc(line, func(match string) string {
+			if strings.Contains(match, redactStart) {
+				return match // Already redacted
+			}
+			sub := pattern.FindStringSubmatch(match)
+			if len(sub) > 1 && sub[1] != "" {
+				return strings.Replace(match, sub[1], redactStart+mask(sub[1])+redactEnd, 1)
+			}
+			return redactStart + mask(match) + redactEnd
+		})
+	}
+	return line
+}
+
+// minSecretLine is the length below which a line of a multi-line secret is
+// not redacted on its own, so that lines such as "{" are left alone
+const minSecretLine = 8
+
+// secretLines splits multi-line secrets, such as private keys and block
+// scalars, into their lines, which is how they show up in a line of text
+func secretLines(fileSecrets []string) []string {
+	var parts []string
+	for _, secret := range fileSecrets {
+		if !strings.Contains(secret, "\n") {
+			parts = append(parts, secret)
+			continue
+		}
+		for _, line := range strings.Split(secret, "\n") {
+			if line = strings.TrimSpace(line); len(line) >= minSecretLine {
+				parts = append(parts, line)
+			}
+		}
+	}
+	return parts
+}
+
+// redactTruncated masks the start of a secret that a truncated line was cut
+// off in, which the full secret no longer matches
+func redactTruncated(line, secret string) string {
+	body, truncated := strings.CutSuffix(line, "...")
+	if !truncated {
+		return line
+	}
+	for i := max(0, len(body)-len(secret)+1); i < len(body); i++ {
+		if strings.HasPrefix(secret, body[i:]) {
+			return body[:i] + redactStart + mask(secret)[:len(body)-i] + redactEnd + "..."
+		}
+	}
+	return line
+}
+
+// highlight replaces the redaction sentinels with the secret style
+func highlight(line string, style textStyle) string {
+	return redacted.ReplaceAllStringFunc(line, func(match string) string {
+		return style.secret(strings.Trim(match, redactStart+redactEnd))
+	})
+}
+
+// unmark removes the redaction sentinels without highlighting
+func unmark(line string) string {
+	return strings.NewReplacer(redactStart, "", redactEnd, "").Replace(line)
+}
@@ -0,0 +1,220 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// JUnit grouping modes
+const (
+	GroupByFile = "file" // One testcase per scanned file with findings
+	GroupByRule = "rule" // One testcase per rule with findings
+)
+
+// checkstyleSeverities maps severities onto Checkstyle severities
+var checkstyleSeverities = map[secrets.Severity]string{
+	secrets.SeverityLow:      "info",
+	secrets.SeverityMedium:   "warning",
+	secrets.SeverityHigh:     "error",
+	secrets.SeverityCritical: "error",
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	Classname string         `xml:"classname,attr"`
+	File      string         `xml:"file,attr,omitempty"`
+	Failures  []junitFailure `xml:"failure"`
+	Errors    []junitFailure `xml:"error"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes findings as a JUnit XML report for the test result views
+// of Jenkins and GitLab. Findings are grouped into one testcase per file or
+// per rule (see GroupByFile and GroupByRule), with one failure per finding.
+// A clean scan is reported as a single passing testcase, and each path that
+// could not be scanned as a testcase with an error.
+func WriteJUnit(w io.Writer, findings []secrets.Finding, info Info, groupBy string) error {
+	groups := make(map[string][]secrets.Finding)
+	for _, f := range findings {
+		key := info.relPath(f.File)
+		if groupBy == GroupByRule {
+			key = f.Rule
+		}
+		groups[key] = append(groups[key], f)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	suite := junitTestSuite{Name: "secret-scan"}
+	for _, name := range names {
+		testCase := junitTestCase{Name: name, Classname: ToolName + "." + groupBy}
+		if groupBy != GroupByRule {
+			testCase.File = name
+		}
+
+		for _, f := range groups[name] {
+			testCase.Failures = append(testCase.Failures, junitFailure{
+				Message: fmt.Sprintf("%s (line %d)", message(f), f.LineNum),
+				Type:    secrets.RuleID(f.Rule),
+				Text:    fmt.Sprintf("File: %s\nLine: %d\nSeverity: %s\nContent: %s", info.relPath(f.File), f.LineNum, f.Severity, redact(f)),
+			})
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Failures++
+	}
+
+	if len(suite.Cases) == 0 {
+		suite.Cases = append(suite.Cases, junitTestCase{Name: "no secrets detected", Classname: ToolName})
+	}
+
+	// Paths that could not be scanned are errored testcases
+	for _, e := range info.Stats.Errors {
+		path := info.relPath(e.Path)
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      path,
+			Classname: ToolName + ".scan-error",
+			File:      path,
+			Errors: []junitFailure{{
+				Message: errorMessage(e),
+				Type:    e.Op,
+				Text:    e.Error(),
+			}},
+		})
+		suite.Errors++
+	}
+	suite.Tests = len(suite.Cases)
+
+	return writeXML(w, junitTestSuites{
+		Name:     ToolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Suites:   []junitTestSuite{suite},
+	})
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr,omitempty"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// WriteCheckstyle writes findings as a Checkstyle XML report for warnings
+// views such as the Jenkins warnings-ng plugin
+func WriteCheckstyle(w io.Writer, findings []secrets.Finding, info Info) error {
+	files := make(map[string]*checkstyleFile)
+	var names []string
+	for _, f := range findings {
+		name := info.relPath(f.File)
+		file, ok := files[name]
+		if !ok {
+			file = &checkstyleFile{Name: name}
+			files[name] = file
+			names = append(names, name)
+		}
+
+		severity := checkstyleSeverities[f.Severity]
+		if severity == "" {
+			severity = "warning"
+		}
+		file.Errors = append(file.Errors, checkstyleError{
+			Line:     f.LineNum,
+			Column:   f.Column,
+			Severity: severity,
+			Message:  message(f),
+			Source:   ToolName + "." + secrets.RuleID(f.Rule),
+		})
+	}
+
+	// Paths that could not be scanned are reported as file-level errors
+	for _, e := range info.Stats.Errors {
+		name := info.relPath(e.Path)
+		file, ok := files[name]
+		if !ok {
+			file = &checkstyleFile{Name: name}
+			files[name] = file
+			names = append(names, name)
+		}
+		file.Errors = append(file.Errors, checkstyleError{
+			Severity: "error",
+			Message:  errorMessage(e),
+			Source:   ToolName + ".scan-error",
+		})
+	}
+	sort.Strings(names)
+
+	report := checkstyleReport{Version: "4.3"}
+	for _, name := range names {
+		report.Files = append(report.Files, *files[name])
+	}
+	return writeXML(w, report)
+}
+
+// writeXML writes an indented XML document with its header
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ParseGroupBy validates a JUnit grouping mode
+func ParseGroupBy(mode string) (string, error) {
+	switch strings.ToLower(mode) {
+	case GroupByFile, "":
+		return GroupByFile, nil
+	case GroupByRule:
+		return GroupByRule, nil
+	}
+	return "", fmt.Errorf("unknown JUnit grouping %q (expected file or rule)", mode)
+}
@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"strings"
+)
+
+// Scope limits how far apart the matches of a composite rule may be
+type Scope string
+
+// Supported composite rule scopes
+const (
+	ScopeLines  Scope = "lines"  // matches within CompositeRule.Within lines of each other
+	ScopeFile   Scope = "file"   // matches anywhere in the same file
+	ScopeObject Scope = "object" // matches in the same structured object, by Finding.Key
+)
+
+// CompositeRule correlates findings of several rules that are much more
+// dangerous together than alone, such as an AWS key ID next to its secret.
+// When all of Rules match within the scope, a single finding is reported
+// for the composite rule and the individual findings are downgraded.
+type CompositeRule struct {
+	Name      string   // Name reported as the rule of the correlated finding
+	Rules     []string // Names of the rules that must all match
+	Scope     Scope    // How close together the matches must be
+	Within    int      // Maximum distance in lines for ScopeLines
+	Severity  Severity // Severity of the correlated finding
+	Downgrade Severity // Severity given to the individual findings
+}
+
+// CompositeRules contains the composite rules applied to the findings of
+// every scanned file.
+//
+// To extend this list, add a rule whose Rules name entries in Patterns or
+// findings produced by Detectors.
+var CompositeRules = []CompositeRule{
+	{
+		Name:      "AWS Credential Pair",
+		Rules:     []string{"AWS Access Key", "AWS Secret Key"},
+		Scope:     ScopeLines,
+		Within:    5,
+		Severity:  SeverityCritical,
+		Downgrade: SeverityLow,
+	},
+}
+
+// correlate applies CompositeRules to the findings of a single file and
+// returns them with any correlated findings appended
+func correlate(findings []Finding) []Finding {
+	for _, rule := range CompositeRules {
+		if len(rule.Rules) < 2 {
+			continue
+		}
+
+		used := make(map[int]bool)
+		for anchor := range findings {
+			if findings[anchor].Rule != rule.Rules[0] || used[anchor] {
+				continue
+			}
+
+			// Find the closest unused match of every other rule
+			group := []int{anchor}
+			for _, name := range rule.Rules[1:] {
+				best := -1
+				for i := range findings {
+					if findings[i].Rule != name || used[i] || !rule.inScope(findings[anchor], findings[i]) {
+						continue
+					}
+					if best < 0 || lineDistance(findings[anchor], findings[i]) < lineDistance(findings[anchor], findings[best]) {
+						best = i
+					}
+				}
+				if best < 0 {
+					group = nil
+					break
+				}
+				group = append(group, best)
+			}
+			if group == nil {
+				continue
+			}
+
+			// The correlated finding is shown at the anchor's line, so it
+			// carries the anchor's secret for that line to be masked
+			correlated := Finding{
+				File:     findings[anchor].File,
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				LineNum:  findings[anchor].LineNum,
+				Column:   findings[anchor].Column,
+				LineText: findings[anchor].LineText,
+				Offset:   findings[anchor].Offset,
+				Key:      findings[anchor].Key,
+				Secret:   findings[anchor].Secret,
+			}
+			for _, i := range group {
+				used[i] = true
+				if rule.Downgrade != "" {
+					findings[i].Severity = rule.Downgrade
+				}
+				correlated.Related = append(correlated.Related, Location{
+					File:    findings[i].File,
+					Rule:    findings[i].Rule,
+					LineNum: findings[i].LineNum,
+				})
+			}
+			findings = append(findings, correlated)
+		}
+	}
+
+	return findings
+}
+
+// inScope reports whether two findings are close enough to be correlated
+func (r CompositeRule) inScope(a, b Finding) bool {
+	switch r.Scope {
+	case ScopeFile:
+		return true
+	case ScopeObject:
+		// Keys without a parent, such as "_authToken@host", do not name
+		// an object that others could share
+		parent := keyParent(a.Key)
+		return parent != "" && parent == keyParent(b.Key)
+	default:
+		return lineDistance(a, b) <= r.Within
+	}
+}
+
+// keyParent returns the structured object containing a finding's key, e.g.
+// "db-credentials.data" for "db-credentials.data.password"
+func keyParent(key string) string {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+// lineDistance returns the number of lines between two findings
+func lineDistance(a, b Finding) int {
+	if a.LineNum > b.LineNum {
+		return a.LineNum - b.LineNum
+	}
+	return b.LineNum - a.LineNum
+}
@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd || openbsd
+
+package secrets
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// threadCPUTime returns the CPU time used so far by the calling OS thread.
+// Callers lock the goroutine to its thread between two readings.
+func threadCPUTime() time.Duration {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_THREAD_CPUTIME_ID, &ts); err != nil {
+		return 0
+	}
+	return time.Duration(ts.Nano())
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || openbsd)
+
+package secrets
+
+import "time"
+
+var processStart = time.Now()
+
+// threadCPUTime falls back to wall time where there is no per-thread CPU
+// clock
+func threadCPUTime() time.Duration {
+	return time.Since(processStart)
+}
@@ -0,0 +1,296 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envReference matches values that are substituted from the environment at
+// runtime, such as ${NPM_TOKEN}, $TOKEN or Maven's ${env.PASSWORD}
+var envReference = regexp.MustCompile(`^\$(?:\{[^}]+\}|[A-Za-z_][A-Za-z0-9_]*)$`)
+
+// npmrcCredential matches the auth settings of an .npmrc file, optionally
+// scoped to a registry, e.g. //registry.npmjs.org/:_authToken=...
+var npmrcCredential = regexp.MustCompile(`^\s*(?://(\S+?)/?:)?(_authToken|_auth|_password)\s*=\s*(.+?)\s*$`)
+
+// detectCredentialFiles reports registry and tool credentials in the files
+// package managers read them from: .npmrc, .pypirc, Docker's config.json,
+// .netrc and Maven's settings.xml. Values substituted from the environment
+// are not reported.
+func detectCredentialFiles(path string, content []byte) []Finding {
+	switch name := filepath.Base(path); {
+	case name == ".npmrc":
+		return npmrcFindings(path, content)
+	case name == ".pypirc":
+		return pypircFindings(path, content)
+	case name == "config.json" && bytes.Contains(content, []byte(`"auths"`)):
+		return dockerConfigFindings(path, content)
+	case name == ".netrc" || name == "_netrc":
+		return netrcFindings(path, content)
+	case name == "settings.xml" && bytes.Contains(content, []byte("<servers>")):
+		return mavenSettingsFindings(path, content)
+	}
+	return nil
+}
+
+// npmrcFindings reports _authToken, _auth and _password settings
+func npmrcFindings(path string, content []byte) []Finding {
+	var findings []Finding
+	for i, line := range splitLines(content) {
+		lineNum := i + 1
+		match := npmrcCredential.FindStringSubmatch(line)
+		if match == nil || isPlaceholder(match[3]) {
+			continue
+		}
+		host := "registry.npmjs.org"
+		if match[1] != "" {
+			host = match[1]
+		}
+		findings = append(findings, credentialFinding(path, content, lineNum, "NPM Registry Credential", host, match[2], match[3]))
+	}
+	return findings
+}
+
+// pypircFindings reports passwords in the repository sections of a .pypirc
+func pypircFindings(path string, content []byte) []Finding {
+	var findings []Finding
+	section := ""
+	repositories := make(map[string]string) // repository URL hosts by section
+	type pending struct {
+		section string
+		line    int
+		value   string
+	}
+	var passwords []pending
+
+	for i, line := range splitLines(content) {
+		lineNum := i + 1
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.Trim(trimmed, "[]")
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			key, value, ok = strings.Cut(trimmed, ":")
+		}
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case "repository":
+			if u, err := url.Parse(value); err == nil && u.Host != "" {
+				repositories[section] = u.Host
+			}
+		case "password":
+			if !isPlaceholder(value) {
+				passwords = append(passwords, pending{section, lineNum, value})
+			}
+		}
+	}
+
+	// The repository URL may follow the password within a section
+	for _, p := range passwords {
+		host := repositories[p.section]
+		if host == "" {
+			host = p.section
+			if p.section == "pypi" {
+				host = "upload.pypi.org"
+			}
+		}
+		findings = append(findings, credentialFinding(path, content, p.line, "PyPI Credential", host, "password", p.value))
+	}
+	return findings
+}
+
+// dockerConfigFindings reports the auths entries of a Docker config.json
+func dockerConfigFindings(path string, content []byte) []Finding {
+	var config struct {
+		Auths map[string]struct {
+			Auth          string `json:"auth"`
+			IdentityToken string `json:"identitytoken"`
+			RegistryToken string `json:"registrytoken"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil
+	}
+
+	// Report hosts in a fixed order rather than the order of the map
+	hosts := make([]string, 0, len(config.Auths))
+	for host := range config.Auths {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var findings []Finding
+	for _, host := range hosts {
+		entry := config.Auths[host]
+		// auth is base64 of "user:password"; an empty password is harmless
+		if decoded, err := base64.StdEncoding.DecodeString(entry.Auth); err == nil {
+			if _, password, ok := strings.Cut(string(decoded), ":"); ok && !isPlaceholder(password) {
+				findings = append(findings, credentialFinding(path, content, valueLine(content, entry.Auth), "Docker Registry Credential", host, "auth", entry.Auth))
+			}
+		}
+		if !isPlaceholder(entry.IdentityToken) {
+			findings = append(findings, credentialFinding(path, content, valueLine(content, entry.IdentityToken), "Docker Registry Credential", host, "identitytoken", entry.IdentityToken))
+		}
+		if !isPlaceholder(entry.RegistryToken) {
+			findings = append(findings, credentialFinding(path, content, valueLine(content, entry.RegistryToken), "Docker Registry Credential", host, "registrytoken", entry.RegistryToken))
+		}
+	}
+	return findings
+}
+
+// netrcFindings reports the password of each machine entry in a .netrc. The
+// format is a stream of tokens, so an entry may span several lines.
+func netrcFindings(path string, content []byte) []Finding {
+	var findings []Finding
+	machine := ""
+	expect := ""
+
+	for i, line := range splitLines(content) {
+		lineNum := i + 1
+		for _, token := range strings.Fields(line) {
+			switch expect {
+			case "machine":
+				machine = token
+			case "password", "account":
+				if !isPlaceholder(token) {
+					findings = append(findings, credentialFinding(path, content, lineNum, "Netrc Credential", machine, expect, token))
+				}
+			}
+
+			if expect != "" {
+				expect = ""
+				continue
+			}
+
+			switch token {
+			case "machine", "password", "account", "login", "macdef":
+				expect = token
+			case "default":
+				machine = "default"
+			}
+		}
+	}
+	return findings
+}
+
+// mavenSettingsFindings reports passwords and passphrases of the servers in
+// a Maven settings.xml. Passwords encrypted with Maven's master password
+// ({...}) are not reported.
+func mavenSettingsFindings(path string, content []byte) []Finding {
+	var findings []Finding
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	inServer := false
+	serverID := ""
+	element := ""
+	type pending struct {
+		kind  string
+		line  int
+		value string
+	}
+	var credentials []pending
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element = t.Name.Local
+			if element == "server" {
+				inServer, serverID, credentials = true, "", nil
+			}
+		case xml.CharData:
+			if !inServer {
+				continue
+			}
+			value := strings.TrimSpace(string(t))
+			switch element {
+			case "id":
+				serverID = value
+			case "password", "passphrase":
+				encrypted := strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}")
+				if !encrypted && !isPlaceholder(value) {
+					line, _ := decoder.InputPos()
+					credentials = append(credentials, pending{element, line, value})
+				}
+			}
+		case xml.EndElement:
+			element = ""
+			if t.Name.Local == "server" {
+				for _, c := range credentials {
+					findings = append(findings, credentialFinding(path, content, c.line, "Maven Server Credential", serverID, c.kind, c.value))
+				}
+				inServer = false
+			}
+		}
+	}
+	return findings
+}
+
+// credentialFinding builds a finding for the credential value of the given
+// kind for a registry host. lineNum is 1-based. The offset covers the value
+// where it appears on the line, or the whole line when it is written
+// differently, such as an XML value with entities.
+func credentialFinding(path string, content []byte, lineNum int, rule, host, kind, value string) Finding {
+	start := getPosition(content, lineNum, 1)
+	lineText := getLineText(content, lineNum)
+	end := start + len(lineText)
+	if i := strings.LastIndex(lineText, value); i >= 0 && value != "" {
+		start, end = start+i, start+i+len(value)
+	}
+
+	return Finding{
+		File:     path,
+		Rule:     rule,
+		LineNum:  lineNum,
+		LineText: sanitizeLineText(lineText),
+		Offset:   []int{start, end},
+		Key:      fmt.Sprintf("%s@%s", kind, host),
+		Secret:   value,
+	}
+}
+
+// isPlaceholder reports whether a credential value is empty or substituted
+// from the environment
+func isPlaceholder(value string) bool {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	return value == "" || envReference.MatchString(value)
+}
+
+// valueLine returns the 1-based line on which a JSON string value appears
+func valueLine(content []byte, value string) int {
+	i := bytes.Index(content, []byte(`"`+value+`"`))
+	if i < 0 {
+		return 1
+	}
+	lineNum, _ := getLineInfo(content, i)
+	return lineNum
+}
+
+// splitLines returns the lines of content without their line endings
+func splitLines(content []byte) []string {
+	var result []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		result = append(result, scanner.Text())
+	}
+	return result
+}
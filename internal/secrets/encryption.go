@@ -0,0 +1,248 @@
+package secrets
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EncryptedFilePatterns are file name patterns for files that must always be
+// committed encrypted. Files matching the creation rules of a .sops.yaml in
+// the same or a parent directory are held to the same policy.
+var EncryptedFilePatterns = []string{"*vault*.yml", "*vault*.yaml"}
+
+var (
+	gitCryptHeader     = []byte("\x00GITCRYPT\x00")
+	ansibleVaultHeader = []byte("$ANSIBLE_VAULT;")
+
+	sopsYAMLMetadata   = regexp.MustCompile(`(?m)^sops:\s*$`)
+	sopsJSONMetadata   = regexp.MustCompile(`"sops"\s*:\s*\{`)
+	sopsDotenvMetadata = regexp.MustCompile(`(?m)^(?:sops_version=|\[sops\])`)
+	sealedSecretKind   = regexp.MustCompile(`(?m)^kind:\s*SealedSecret\s*$`)
+	plainSecretKind    = regexp.MustCompile(`(?m)^kind:\s*Secret\s*$`)
+
+	// sopsEncryptedValue matches a value encrypted by SOPS
+	sopsEncryptedValue = regexp.MustCompile(`ENC\[[A-Z0-9_]+,data:`)
+
+	// sopsMetadataBlock matches the line opening the SOPS metadata in a YAML
+	// or JSON file, whose nested lines hold the encrypted data keys
+	sopsMetadataBlock = regexp.MustCompile(`^(?:sops:|"sops"\s*:\s*\{)\s*$`)
+)
+
+// encryptionFormat returns the name of the at-rest encryption format used by
+// a file, or "" when the file is not encrypted
+func encryptionFormat(content []byte) string {
+	if format := fileEncryptionFormat(content); format != "" {
+		return format
+	}
+	switch {
+	case sopsYAMLMetadata.Match(content) || sopsJSONMetadata.Match(content) || sopsDotenvMetadata.Match(content):
+		return "sops"
+	case sealedSecretKind.Match(content) && !plainSecretKind.Match(content):
+		return "sealed-secrets"
+	}
+	return ""
+}
+
+// fileEncryptionFormat returns the name of the encryption format of a file
+// that is encrypted as a whole, or "". SOPS and SealedSecrets encrypt
+// individual values and leave the rest of the file in plaintext, so their
+// files are scanned and only the encrypted lines are skipped.
+func fileEncryptionFormat(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, gitCryptHeader):
+		return "git-crypt"
+	case bytes.HasPrefix(bytes.TrimSpace(content), ansibleVaultHeader):
+		return "ansible-vault"
+	}
+	return ""
+}
+
+// encryptedLines returns the line numbers covered by inline encrypted values:
+// Ansible "!vault |" blocks, SOPS ENC[...] values and metadata, and the
+// encryptedData of SealedSecret documents. Keys that SOPS leaves in
+// plaintext, through unencrypted_suffix or unencrypted_regex, are not
+// covered.
+func encryptedLines(content []byte) map[int]bool {
+	lines := make(map[int]bool)
+	if !bytes.Contains(content, []byte("!vault")) && !bytes.Contains(content, []byte("ENC[")) &&
+		!bytes.Contains(content, []byte("sops")) && !sealedSecretKind.Match(content) {
+		return lines
+	}
+
+	sopsDotenv := sopsDotenvMetadata.Match(content)
+	inBlock := false
+	blockIndent := 0
+	for i, line := range strings.Split(string(content), "\n") {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		trimmed := strings.TrimSpace(line)
+
+		if inBlock {
+			if trimmed == "" || indent > blockIndent {
+				lines[i+1] = true
+				continue
+			}
+			inBlock = false
+		}
+
+		switch {
+		case strings.HasSuffix(trimmed, "!vault |") || strings.HasSuffix(trimmed, "!vault |-"),
+			sopsMetadataBlock.MatchString(trimmed):
+			lines[i+1] = true
+			inBlock = true
+			blockIndent = indent
+		case sopsEncryptedValue.MatchString(line), sopsDotenv && strings.HasPrefix(line, "sops_"):
+			lines[i+1] = true
+		}
+	}
+
+	for line := range sealedSecretLines(content) {
+		lines[line] = true
+	}
+	return lines
+}
+
+// sealedSecretLines returns the lines holding the encryptedData of
+// SealedSecret documents. Other documents in the same file, and the
+// plaintext template of a SealedSecret, are left to be scanned.
+func sealedSecretLines(content []byte) map[int]bool {
+	lines := make(map[int]bool)
+	if !sealedSecretKind.Match(content) {
+		return lines
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			break
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+		if kind := mappingValue(root, "kind"); kind == nil || kind.Value != "SealedSecret" {
+			continue
+		}
+
+		data := mappingValue(mappingValue(root, "spec"), "encryptedData")
+		if data == nil || data.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(data.Content); i += 2 {
+			key, value := data.Content[i], data.Content[i+1]
+			end := value.Line + strings.Count(strings.TrimRight(value.Value, "\n"), "\n")
+			if value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+				// Block scalars start on the line after their indicator
+				end++
+			}
+			for line := key.Line; line <= end; line++ {
+				lines[line] = true
+			}
+		}
+	}
+	return lines
+}
+
+// encryptionPolicy decides which files must be encrypted, combining
+// EncryptedFilePatterns with the creation rules of .sops.yaml files found
+// between a file and the scan root
+type encryptionPolicy struct {
+	root  string
+	rules map[string][]*regexp.Regexp // .sops.yaml path_regex rules by directory
+}
+
+func newEncryptionPolicy(root string) *encryptionPolicy {
+	root = filepath.Clean(root)
+	// A file scanned on its own only answers to its own directory
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+	return &encryptionPolicy{root: root, rules: make(map[string][]*regexp.Regexp)}
+}
+
+// requiresEncryption reports whether the file at path must be encrypted
+func (p *encryptionPolicy) requiresEncryption(path string) bool {
+	base := filepath.Base(path)
+	for _, pattern := range EncryptedFilePatterns {
+		if matched, _ := filepath.Match(pattern, base); matched {
+			return true
+		}
+	}
+
+	// Like sops itself, use the nearest .sops.yaml above the file
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if rules := p.sopsRules(dir); rules != nil {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return false
+			}
+			rel = filepath.ToSlash(rel)
+			for _, rule := range rules {
+				if rule.MatchString(rel) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if dir == p.root || dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+// sopsRules returns the path_regex creation rules of the .sops.yaml in dir,
+// or nil when there is none
+func (p *encryptionPolicy) sopsRules(dir string) []*regexp.Regexp {
+	if rules, ok := p.rules[dir]; ok {
+		return rules
+	}
+
+	var rules []*regexp.Regexp
+	if content, err := os.ReadFile(filepath.Join(dir, ".sops.yaml")); err == nil {
+		var config struct {
+			CreationRules []struct {
+				PathRegex string `yaml:"path_regex"`
+			} `yaml:"creation_rules"`
+		}
+		if yaml.Unmarshal(content, &config) == nil {
+			// A config without path rules still marks the directory as
+			// handled so that parent configs are not consulted
+			rules = []*regexp.Regexp{}
+			for _, rule := range config.CreationRules {
+				if rule.PathRegex == "" {
+					continue
+				}
+				if re, err := regexp.Compile(rule.PathRegex); err == nil {
+					rules = append(rules, re)
+				}
+			}
+		}
+	}
+
+	p.rules[dir] = rules
+	return rules
+}
+
+// unencryptedFinding reports a plaintext file that policy says must be
+// encrypted
+func unencryptedFinding(path string, content []byte) Finding {
+	end := bytes.IndexByte(content, '\n')
+	if end < 0 {
+		end = len(content)
+	}
+
+	return Finding{
+		File:     path,
+		Rule:     "Unencrypted Secret File",
+		Severity: SeverityHigh,
+		LineNum:  1,
+		LineText: sanitizeLineText(getLineText(content, 1)),
+		Offset:   []int{0, end},
+	}
+}
@@ -0,0 +1,164 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanDirEncryptedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	testFiles := map[string]string{
+		".sops.yaml": `creation_rules:
+  - path_regex: secrets/.*\.yaml$
+    age: age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+`,
+		// Encrypted with sops, so the password value is safe, but the key
+		// left in plaintext by unencrypted_regex is not
+		"secrets/prod.yaml": `password: ENC[AES256_GCM,data:Tr7o=,iv:1=,tag:k=,type:str]
+api_key = "abcdefghijklmnopqrstuvwxyz123456"
+sops:
+    mac: ENC[AES256_GCM,data:abc=,iv:def=,tag:ghi=,type:str]
+    age:
+        - recipient: age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+          enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            api_key = "abcdefghijklmnopqrstuvwxyz123456"
+            -----END AGE ENCRYPTED FILE-----
+    unencrypted_regex: ^api_key
+    version: 3.7.3
+`,
+		// The SealedSecret is safe, the ConfigMap next to it is not
+		"deploy/app.yaml": `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: app
+spec:
+  encryptedData:
+    password: AgBy3i4OJSWKPiTySYZZA9rO43cGDEq
+  template:
+    metadata:
+      name: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  settings: |
+    api_key = "zyxwvutsrqponmlkjihgfedcba654321"
+`,
+		// Sealed values written as a mapping are skipped too
+		"deploy/sealed.yaml": `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: db
+spec:
+  encryptedData:
+    password: AgBy3i4OJSWKPiTySYZZA9rO43cGDEq
+    api_key: |
+      api_key = "AgBy3i4OJSWKPiTySYZZA9rO43cGDEq"
+`,
+		// Matches the .sops.yaml creation rule but was committed in plaintext
+		"secrets/staging.yaml": `password: "plaintext_password"
+`,
+		// Matches the default vault file pattern but was committed in plaintext
+		"group_vars/vault.yml": `db_password: hunter2
+`,
+		"group_vars/vault_encrypted.yml": `$ANSIBLE_VAULT;1.1;AES256
+62313365396662343061393464336163383764373764613633653634306231386433626436623361
+`,
+		// Inline vault values are encrypted but the rest of the file is not
+		"group_vars/all.yml": `db_user: admin
+db_password: !vault |
+  $ANSIBLE_VAULT;1.1;AES256
+  api_key = "abcdefghijklmnopqrstuvwxyz123456"
+api_key = "zyxwvutsrqponmlkjihgfedcba654321"
+`,
+	}
+
+	for name, content := range testFiles {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file %s: %v", name, err)
+		}
+	}
+
+	findings, err := ScanDir(tempDir, nil)
+	if err != nil {
+		t.Fatalf("ScanDir failed: %v", err)
+	}
+
+	type key struct {
+		file string
+		rule string
+		line int
+	}
+	got := make(map[key]Finding)
+	for _, f := range findings {
+		rel, _ := filepath.Rel(tempDir, f.File)
+		got[key{filepath.ToSlash(rel), f.Rule, f.LineNum}] = f
+	}
+
+	expected := []key{
+		{"secrets/staging.yaml", "Unencrypted Secret File", 1},
+		{"group_vars/vault.yml", "Unencrypted Secret File", 1},
+		{"group_vars/all.yml", "Generic API Key", 5},
+		{"secrets/prod.yaml", "Generic API Key", 2},
+		{"deploy/app.yaml", "Generic API Key", 18},
+	}
+	for _, k := range expected {
+		f, ok := got[k]
+		if !ok {
+			t.Errorf("Expected finding %+v", k)
+			continue
+		}
+		if k.rule == "Unencrypted Secret File" && f.Severity != SeverityHigh {
+			t.Errorf("Expected high severity for %s, got %s", k.file, f.Severity)
+		}
+	}
+	if len(findings) != len(expected) {
+		t.Errorf("Expected %d findings, got %d: %+v", len(expected), len(findings), findings)
+	}
+}
+
+func TestScanFileEncryptionPolicyRoot(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		// Above the scanned file's directory, so outside the scan
+		".sops.yaml":               "creation_rules:\n  - path_regex: \\.yaml$\n",
+		"app/config/settings.yaml": "password: \"plaintext_password\"\n",
+		// Next to the scanned file, so it applies
+		"app/secrets/.sops.yaml": "creation_rules:\n  - path_regex: \\.yaml$\n",
+		"app/secrets/prod.yaml":  "password: \"plaintext_password\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file %s: %v", name, err)
+		}
+	}
+
+	for name, want := range map[string]bool{"app/config/settings.yaml": false, "app/secrets/prod.yaml": true} {
+		findings, err := ScanDir(filepath.Join(tempDir, name), nil)
+		if err != nil {
+			t.Fatalf("ScanDir failed: %v", err)
+		}
+		got := false
+		for _, f := range findings {
+			if f.Rule == "Unencrypted Secret File" {
+				got = true
+			}
+		}
+		if got != want {
+			t.Errorf("%s: expected unencrypted finding %v, got %+v", name, want, findings)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package secrets
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Rules reported for environment variables by ScanEnv
+const (
+	RuleSecretVariable    = "Secret Environment Variable"
+	RuleForbiddenVariable = "Forbidden Environment Variable"
+)
+
+// EnvVar is an environment variable from the process environment or a dump
+type EnvVar struct {
+	Name  string
+	Value string
+	Line  int // Line of the dump the variable starts on, or its position in the environment
+}
+
+// envAssignment matches the start of a variable in the output of env,
+// printenv, set, export -p or declare -x
+var envAssignment = regexp.MustCompile(`^(?:export |declare -x )?([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+// envDeclaration matches a variable that is exported without a value, as
+// export -p and declare -x print OLDPWD
+var envDeclaration = regexp.MustCompile(`^(?:export|declare -x) ([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// secretVariableName matches variable names that suggest a secret value
+var secretVariableName = regexp.MustCompile(`(?i)(?:^|_)(?:TOKEN|SECRET|PASSWORD|PASSWD|PASSPHRASE|API_?KEY|PRIVATE_?KEY|ACCESS_?KEY|CREDENTIALS?|AUTH)(?:_|$)`)
+
+// nonSecretVariableName matches names that point at or describe a secret
+// rather than hold it, such as TOKEN_FILE or SECRET_NAME
+var nonSecretVariableName = regexp.MustCompile(`(?i)_(?:FILE|PATH|DIR|URL|URI|ENDPOINT|NAME|TYPE|ID|HOST|PORT|USER|USERNAME|EXPIRY|EXPIRES|TTL|ENABLED|MODE)$`)
+
+// maskedValue matches the placeholders CI systems print in place of masked
+// values: *** in GitHub Actions and Jenkins, [MASKED] in GitLab
+var maskedValue = regexp.MustCompile(`(?i)^(?:\*{3,}|\[MASKED\]|\[REDACTED\]|<redacted>)$`)
+
+// ParseEnv reads environment variables from a KEY=VALUE dump, such as the
+// output of env, printenv or export -p. A line continues the value of the
+// previous variable while that value is inside an open quote, as multi-line
+// values of export -p are; other lines that do not start a variable are
+// skipped. Variables declared without a value have an empty value.
+func ParseEnv(r io.Reader) ([]EnvVar, error) {
+	var vars []EnvVar
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(vars) > 0 && openQuote(vars[len(vars)-1].Value) {
+			vars[len(vars)-1].Value += "\n" + text
+		} else if m := envAssignment.FindStringSubmatch(text); m != nil {
+			vars = append(vars, EnvVar{Name: m[1], Value: m[2], Line: line})
+		} else if m := envDeclaration.FindStringSubmatch(text); m != nil {
+			vars = append(vars, EnvVar{Name: m[1], Line: line})
+		}
+	}
+
+	// export -p and declare -x quote their values
+	for i := range vars {
+		value := vars[i].Value
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			vars[i].Value = strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\$`, `$`, "\\`", "`").Replace(value[1 : len(value)-1])
+		}
+	}
+	return vars, scanner.Err()
+}
+
+// openQuote reports whether a value starts with a quote that it does not
+// close, so that the value continues on the next line
+func openQuote(value string) bool {
+	if value == "" {
+		return false
+	}
+	switch value[0] {
+	case '"':
+		for i := 1; i < len(value); i++ {
+			switch value[i] {
+			case '\\':
+				i++ // Skip the escaped character
+			case '"':
+				return false
+			}
+		}
+		return true
+	case '\'':
+		return !strings.Contains(value[1:], "'")
+	}
+	return false
+}
+
+// EnvFromEnviron converts the output of os.Environ into variables
+func EnvFromEnviron(environ []string) []EnvVar {
+	vars := make([]EnvVar, 0, len(environ))
+	for i, entry := range environ {
+		name, value, _ := strings.Cut(entry, "=")
+		vars = append(vars, EnvVar{Name: name, Value: value, Line: i + 1})
+	}
+	return vars
+}
+
+// ScanEnv checks environment variables for secrets that are exposed to
+// anything printing the environment. A variable is reported when its
+// assignment matches one of the Patterns, or when its name suggests a secret
+// and its value looks like one. Values masked by the CI system are not
+// reported. Variables whose names match one of the neverExport patterns
+// (e.g. "VAULT_*") are reported whatever their value. Each variable is
+// reported once, under the most severe rule it matches. Findings are
+// reported in source, and never include the value in LineText.
+func ScanEnv(source string, vars []EnvVar, neverExport []string) []Finding {
+	var findings []Finding
+	for _, v := range vars {
+		if f, ok := scanEnvVar(source, v, neverExport); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// scanEnvVar returns the finding for a variable under the most severe rule
+// it matches, the first one reported on a tie
+func scanEnvVar(source string, v EnvVar, neverExport []string) (Finding, bool) {
+	var best Finding
+	found := false
+	report := func(rule, secret string) {
+		if found && best.Severity.AtLeast(SeverityFor(rule)) {
+			return
+		}
+		best = Finding{
+			File:     source,
+			Rule:     rule,
+			Severity: SeverityFor(rule),
+			LineNum:  v.Line,
+			Column:   1,
+			LineText: v.Name + "=" + maskEnvValue(v.Value),
+			Key:      v.Name,
+			Secret:   secret,
+		}
+		found = true
+	}
+
+	for _, pattern := range neverExport {
+		if matched, _ := filepath.Match(pattern, v.Name); matched {
+			report(RuleForbiddenVariable, v.Value)
+			break
+		}
+	}
+	if v.Value == "" || maskedValue.MatchString(v.Value) {
+		return best, found
+	}
+
+	// Patterns see the assignment, so that rules keyed on names apply
+	assignment := []byte(v.Name + "=" + v.Value)
+	matched := false
+	for _, name := range sortedPatternNames() {
+		if loc := Patterns[name].FindSubmatchIndex(assignment); loc != nil {
+			report(name, matchedSecret(assignment, loc))
+			matched = true
+		}
+	}
+	if !matched && pemKeyBegin.MatchString(v.Value) {
+		report("Private Key", v.Value)
+		matched = true
+	}
+	if !matched && isSecretVariable(v) {
+		report(RuleSecretVariable, v.Value)
+	}
+	return best, found
+}
+
+// isSecretVariable reports whether a variable's name suggests a secret and
+// its value could be one, rather than a flag, a number, a path or a
+// reference to another variable
+func isSecretVariable(v EnvVar) bool {
+	if !secretVariableName.MatchString(v.Name) || nonSecretVariableName.MatchString(v.Name) {
+		return false
+	}
+
+	value := strings.TrimSpace(v.Value)
+	if len(value) < 8 || strings.ContainsAny(value[:1], "/~.$") {
+		return false
+	}
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "enabled", "disabled":
+		return false
+	}
+	return strings.TrimLeft(value, "0123456789") != ""
+}
+
+// maskEnvValue hides a value, showing at most its first few characters
+func maskEnvValue(value string) string {
+	if value == "" || maskedValue.MatchString(value) {
+		return value
+	}
+	return value[:min(4, len(value)/4)] + "***"
+}
@@ -0,0 +1,246 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubeconfigUserKeys are the fields of a kubeconfig user entry that hold
+// credentials rather than references to files on disk
+var kubeconfigUserKeys = []string{"client-key-data", "token", "password"}
+
+// kubeconfigAuthProviderKeys are the fields of a user's auth-provider config
+// that hold tokens issued by the provider
+var kubeconfigAuthProviderKeys = []string{"id-token", "refresh-token", "access-token", "client-secret"}
+
+// kubeconfigSecretEnv matches the names of exec plugin environment variables
+// that hold a credential, such as VAULT_TOKEN or AWS_SECRET_ACCESS_KEY, but
+// not ones that point at it, such as TOKEN_FILE
+var kubeconfigSecretEnv = regexp.MustCompile(`(?i)(?:^|_)(?:TOKEN|SECRET|PASSWORD|API_?KEY|ACCESS_?KEY|CREDENTIALS?)$`)
+
+// detectKubernetes reports the values of Kubernetes Secret manifests and the
+// credentials embedded in kubeconfig files. SealedSecrets and values
+// encrypted by SOPS are safe to commit and are skipped.
+func detectKubernetes(path string, content []byte) []Finding {
+	if !bytes.Contains(content, []byte("kind:")) {
+		return nil
+	}
+
+	var findings []Finding
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		// Stop at the end of the stream or at the first document that isn't
+		// valid YAML; the regex patterns still cover the rest of the file
+		if err := decoder.Decode(&doc); err != nil {
+			break
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := doc.Content[0]
+
+		kind := mappingValue(root, "kind")
+		if kind == nil {
+			continue
+		}
+
+		// SealedSecrets use their own kind and are encrypted for the
+		// cluster, so only plain Secrets are reported
+		switch kind.Value {
+		case "Secret":
+			findings = append(findings, secretManifestFindings(path, content, root)...)
+		case "Config":
+			findings = append(findings, kubeconfigFindings(path, content, root)...)
+		}
+	}
+
+	return findings
+}
+
+// secretManifestFindings reports every key under data and stringData of a
+// Secret manifest
+func secretManifestFindings(path string, content []byte, root *yaml.Node) []Finding {
+	name := "<unnamed>"
+	if metadata := mappingValue(root, "metadata"); metadata != nil {
+		if n := mappingValue(metadata, "name"); n != nil {
+			name = n.Value
+		}
+	}
+
+	var findings []Finding
+	for _, section := range []string{"data", "stringData"} {
+		values := mappingValue(root, section)
+		if values == nil || values.Kind != yaml.MappingNode {
+			continue
+		}
+
+		for i := 0; i+1 < len(values.Content); i += 2 {
+			key, value := values.Content[i], values.Content[i+1]
+			if value.Kind != yaml.ScalarNode {
+				continue
+			}
+
+			plain := value.Value
+			if sopsEncryptedValue.MatchString(plain) {
+				continue
+			}
+			if section == "data" {
+				decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(plain))
+				if err != nil {
+					// Not valid base64, so the API server would reject it
+					continue
+				}
+				plain = string(decoded)
+			}
+			if strings.TrimSpace(plain) == "" {
+				continue
+			}
+
+			findings = append(findings, structuredFinding(path, content, value, "Kubernetes Secret",
+				fmt.Sprintf("%s.%s.%s", name, section, key.Value)))
+		}
+	}
+
+	return findings
+}
+
+// kubeconfigFindings reports the inline credentials of each kubeconfig user
+func kubeconfigFindings(path string, content []byte, root *yaml.Node) []Finding {
+	users := mappingValue(root, "users")
+	if users == nil || users.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	var findings []Finding
+	for _, entry := range users.Content {
+		user := mappingValue(entry, "user")
+		if user == nil {
+			continue
+		}
+
+		name := "<unnamed>"
+		if n := mappingValue(entry, "name"); n != nil {
+			name = n.Value
+		}
+
+		for _, field := range kubeconfigUserKeys {
+			value := mappingValue(user, field)
+			if value == nil || value.Kind != yaml.ScalarNode || value.Value == "" {
+				continue
+			}
+			findings = append(findings, structuredFinding(path, content, value, "Kubeconfig Credential",
+				fmt.Sprintf("users.%s.%s", name, field)))
+		}
+
+		// Tokens cached by an auth provider such as oidc or gcp
+		config := mappingValue(mappingValue(user, "auth-provider"), "config")
+		for _, field := range kubeconfigAuthProviderKeys {
+			value := mappingValue(config, field)
+			if value == nil || value.Kind != yaml.ScalarNode || value.Value == "" {
+				continue
+			}
+			findings = append(findings, structuredFinding(path, content, value, "Kubeconfig Credential",
+				fmt.Sprintf("users.%s.auth-provider.config.%s", name, field)))
+		}
+
+		// Environment passed to an exec credential plugin, of which only
+		// variables that look like secrets are reported
+		env := mappingValue(mappingValue(user, "exec"), "env")
+		if env == nil || env.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, entry := range env.Content {
+			envName, value := mappingValue(entry, "name"), mappingValue(entry, "value")
+			if envName == nil || value == nil || value.Kind != yaml.ScalarNode {
+				continue
+			}
+			if !kubeconfigSecretEnv.MatchString(envName.Value) || value.Value == "" || strings.HasPrefix(value.Value, "$") {
+				continue
+			}
+			findings = append(findings, structuredFinding(path, content, value, "Kubeconfig Credential",
+				fmt.Sprintf("users.%s.exec.env.%s", name, envName.Value)))
+		}
+	}
+
+	return findings
+}
+
+// structuredFinding builds a finding located at a YAML value node
+func structuredFinding(path string, content []byte, value *yaml.Node, rule, key string) Finding {
+	f := Finding{
+		File:     path,
+		Rule:     rule,
+		LineNum:  value.Line,
+		LineText: sanitizeLineText(getLineText(content, value.Line)),
+		Key:      key,
+		Secret:   value.Value,
+	}
+
+	// Block scalars such as "tls.key: |" hold their value on the lines
+	// after the key
+	if value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		f.EndLine = value.Line + strings.Count(strings.TrimRight(value.Value, "\n"), "\n") + 1
+	}
+
+	start, end := scalarSpan(content, value, f.EndLine)
+	f.Offset = []int{start, end}
+	return f
+}
+
+// scalarSpan returns where the text of a scalar starts and ends in content,
+// without its quotes. The decoded value cannot be used for the length, since
+// escapes, folding and indentation make it differ from the source.
+func scalarSpan(content []byte, value *yaml.Node, endLine int) (int, int) {
+	start := getPosition(content, value.Line, value.Column)
+
+	switch {
+	case value.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 && start < len(content):
+		// The value ends at the first quote that is not escaped: by a
+		// backslash in double quotes, or by doubling it in single quotes
+		quote := content[start]
+		start++
+		for end := start; end < len(content); end++ {
+			switch {
+			case quote == '"' && content[end] == '\\':
+				end++
+			case content[end] != quote:
+			case quote == '\'' && end+1 < len(content) && content[end+1] == '\'':
+				end++
+			default:
+				return start, end
+			}
+		}
+		return start, len(content)
+
+	case endLine > value.Line:
+		// A block scalar runs from the indented line after its indicator to
+		// the end of its last line
+		start = getPosition(content, value.Line+1, 1)
+		for start < len(content) && (content[start] == ' ' || content[start] == '\t') {
+			start++
+		}
+		end := getPosition(content, endLine, 1) + len(getLineText(content, endLine))
+		return start, max(start, min(end, len(content)))
+	}
+
+	return start, min(start+len(value.Value), len(content))
+}
+
+// mappingValue returns the value node for key in a YAML mapping, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+)
+
+var (
+	// pemPrivateKey matches a complete PEM private key, either spread over
+	// several lines or with escaped "\n" line breaks inside a JSON string
+	pemPrivateKey = regexp.MustCompile(`(?s)-----BEGIN ((?:[A-Z0-9]+ )*PRIVATE KEY)-----(.+?)-----END (?:[A-Z0-9]+ )*PRIVATE KEY-----`)
+
+	serviceAccountType = regexp.MustCompile(`"type"\s*:\s*"service_account"`)
+)
+
+// minPEMBodyLength is the shortest key body reported as a real key, so that
+// documentation placeholders such as "..." are not
+const minPEMBodyLength = 64
+
+// detectMultiline reports secrets that span several lines, or that are only
+// recognizable as a whole: GCP service account keys and complete PEM private
+// keys. Each secret is reported once with the range of lines it covers.
+func detectMultiline(path string, content []byte) []Finding {
+	var findings []Finding
+
+	// Service account keys contain a PEM key that must not be reported again
+	var covered [][]int
+	if serviceAccountType.Match(content) {
+		if f, ok := serviceAccountFinding(path, content); ok {
+			findings = append(findings, f)
+			covered = append(covered, f.Offset)
+		}
+	}
+
+	for _, loc := range pemPrivateKey.FindAllSubmatchIndex(content, -1) {
+		if insideAny(loc[0], covered) {
+			continue
+		}
+		if len(bytes.TrimSpace(content[loc[4]:loc[5]])) < minPEMBodyLength {
+			continue
+		}
+		findings = append(findings, rangeFinding(path, content, "Private Key", loc[0], loc[1]))
+	}
+
+	return findings
+}
+
+// serviceAccountFinding reports a GCP service account key file
+func serviceAccountFinding(path string, content []byte) (Finding, bool) {
+	var key struct {
+		Type        string `json:"type"`
+		PrivateKey  string `json:"private_key"`
+		ClientEmail string `json:"client_email"`
+	}
+	if err := json.Unmarshal(content, &key); err != nil || key.Type != "service_account" || key.PrivateKey == "" {
+		return Finding{}, false
+	}
+
+	start := bytes.IndexByte(content, '{')
+	end := bytes.LastIndexByte(content, '}') + 1
+	f := rangeFinding(path, content, "GCP Service Account Key", start, end)
+	f.Key = key.ClientEmail
+
+	// The secret is the private key as it is written in the file, escapes
+	// included
+	if span, ok := jsonValueSpans(content)[jsonPath("private_key")]; ok {
+		f.Secret = string(bytes.Trim(content[span[0]:span[1]], `"`))
+	}
+
+	// The client email identifies the account without revealing the key
+	if i := bytes.Index(content, []byte(`"client_email"`)); i >= 0 {
+		_, line := getLineInfo(content, i)
+		f.LineText = sanitizeLineText(line)
+	}
+	return f, true
+}
+
+// rangeFinding builds a finding covering content[start:end], which may span
+// several lines
+func rangeFinding(path string, content []byte, rule string, start, end int) Finding {
+	lineNum, lineText := getLineInfo(content, start)
+	endLine, _ := getLineInfo(content, end-1)
+
+	return Finding{
+		File:     path,
+		Rule:     rule,
+		LineNum:  lineNum,
+		EndLine:  endLine,
+		LineText: sanitizeLineText(lineText),
+		Offset:   []int{start, end},
+		Secret:   string(content[start:end]),
+	}
+}
+
+// insideAny reports whether a position falls within any of the ranges
+func insideAny(pos int, ranges [][]int) bool {
+	for _, r := range ranges {
+		if pos >= r[0] && pos < r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ package secrets
 
 import (
 	"regexp"
+	"strings"
 )
 
 // Patterns contains regex patterns for detecting various types of credentials.
@@ -34,6 +35,9 @@ var Patterns = map[string]*regexp.Regexp{
 	"GitLab Token": regexp.MustCompile(`(?i)gitlab[_\-]?token[\s]*=[\s]*['"]?([0-9a-zA-Z_\-]{20,64})['"]?`),
 	"NPM Token":    regexp.MustCompile(`(?i)(?:NPM_TOKEN|npm_token)[\s]*=[\s]*['"]?([0-9a-zA-Z]{36})['"]?`),
 
+	// Chat & Collaboration Tokens
+	"Slack Token": regexp.MustCompile(`\b(xox[abposr]-[0-9A-Za-z\-]{10,72})`),
+
 	// Generic Credentials
 	"Generic API Key":     regexp.MustCompile(`(?i)api[_\-]?key[\s]*=[\s]*['"]?([0-9a-zA-Z_\-]{20,80})['"]?`),
 	"Private Key":         regexp.MustCompile(`-----BEGIN (?:RSA|DSA|EC|OPENSSH) PRIVATE KEY-----`),
@@ -49,3 +53,12 @@ var Patterns = map[string]*regexp.Regexp{
 	"Basic Auth":            regexp.MustCompile(`Authorization: Basic [a-zA-Z0-9+/=]+`),
 	"Bearer Token":          regexp.MustCompile(`Authorization: Bearer [a-zA-Z0-9\-_=]+\.[a-zA-Z0-9\-_=]+\.[a-zA-Z0-9\-_=]+`),
 }
+
+// nonIDChars matches the characters that separate the words of a rule ID
+var nonIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// RuleID converts a rule name into a stable identifier, e.g. "GitHub Token"
+// becomes "github-token"
+func RuleID(name string) string {
+	return strings.Trim(nonIDChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,239 @@
+package secrets
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// LiteralRule is the rule ID shown for masked values given to NewRedactor
+const LiteralRule = "literal"
+
+// maxRedactLine is the length after which a line that has not ended yet is
+// redacted and written anyway, so that output without newlines is not held
+// back forever
+const maxRedactLine = 64 * 1024
+
+// redactOverlap is how much of a long line is held back when it is written
+// early, so that a secret cut off at the end is matched whole with the text
+// that follows. Patterns without an upper bound can match more, but secrets
+// are much shorter.
+const redactOverlap = 4 * 1024
+
+// maxPEMKeyLines is the most lines masked as the body of a private key
+// without an end marker. A 16384-bit RSA key has about 160 lines.
+const maxPEMKeyLines = 200
+
+// pemKeyBegin and pemKeyEnd delimit the body of a PEM private key, which is
+// spread over lines that no pattern matches on their own
+var (
+	pemKeyBegin = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----`)
+	pemKeyEnd   = regexp.MustCompile(`-----END [A-Z0-9 ]*PRIVATE KEY-----`)
+
+	// pemKeyBody matches a line of base64 in a key body, or one of the
+	// headers of an encrypted key
+	pemKeyBody = regexp.MustCompile(`^\s*(?:[A-Za-z0-9+/]+=*|(?:Proc-Type|DEK-Info): .*)\s*$`)
+)
+
+// Redactor masks secrets in a stream of text, such as the output of a CI job
+// on its way to the log. Every match of Patterns is replaced with
+// "***[rule-id]***", as are the lines of PEM private keys and any of the
+// literal values it was created with.
+//
+// Text is redacted a line at a time and each line is written as soon as it
+// is complete, so a secret split across writes is still matched. Close
+// must be called to write a final line without a newline.
+type Redactor struct {
+	w        io.Writer
+	rules    []string // Names of Patterns in a fixed order
+	literals [][]byte // Longest first, so that overlapping values are masked whole
+	line     []byte   // Incomplete line waiting for its newline
+	inKey    bool     // Inside the body of a PEM private key
+	keyLines int      // Lines of the current private key body so far
+	overlap  int      // Length held back when a long line is written early
+}
+
+// NewRedactor returns a Redactor that writes redacted text to w and also
+// masks every occurrence of the literal values
+func NewRedactor(w io.Writer, literals []string) *Redactor {
+	r := &Redactor{w: w, rules: sortedPatternNames()}
+	for _, literal := range literals {
+		if literal != "" {
+			r.literals = append(r.literals, []byte(literal))
+		}
+	}
+	sort.SliceStable(r.literals, func(i, j int) bool {
+		return len(r.literals[i]) > len(r.literals[j])
+	})
+	r.overlap = redactOverlap
+	if len(r.literals) > 0 {
+		r.overlap = max(r.overlap, len(r.literals[0]))
+	}
+	return r
+}
+
+// Write redacts and writes every line completed by p, keeping the rest until
+// its newline arrives
+func (r *Redactor) Write(p []byte) (int, error) {
+	r.line = append(r.line, p...)
+
+	var out []byte
+	for {
+		end := bytes.IndexByte(r.line, '\n')
+		if end < 0 {
+			break
+		}
+		out = append(out, r.redactLine(r.line[:end])...)
+		out = append(out, '\n')
+		r.line = r.line[end+1:]
+	}
+	if len(r.line) > maxRedactLine {
+		cut := r.flushPoint(r.line)
+		out = append(out, r.redactLine(r.line[:cut])...)
+		r.line = r.line[cut:]
+	}
+
+	// Keep the pending line in a buffer of its own rather than pinning p
+	r.line = append([]byte(nil), r.line...)
+
+	if len(out) > 0 {
+		if _, err := r.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close redacts and writes the final line if it has no newline
+func (r *Redactor) Close() error {
+	if len(r.line) == 0 {
+		return nil
+	}
+	line := r.redactLine(r.line)
+	r.line = nil
+	_, err := r.w.Write(line)
+	return err
+}
+
+// flushPoint returns where to cut a long line that is written before its
+// newline: far enough from the end to hold back a secret that has only
+// partly arrived, and never through a match
+func (r *Redactor) flushPoint(line []byte) int {
+	cut := len(line) - r.overlap
+	spans := r.matchSpans(line)
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start > spans[j].start })
+	for _, span := range spans {
+		if span.start < cut && span.end > cut {
+			cut = span.start
+		}
+	}
+	if cut <= 0 {
+		// A single match fills the line; there is nothing better to do
+		return len(line)
+	}
+	return cut
+}
+
+// redactLine masks the secrets in one line, without its newline
+func (r *Redactor) redactLine(line []byte) []byte {
+	// The body of a private key is masked line by line until its end
+	// marker. A line that does not look like part of a key, or a key that
+	// runs on for too long, means the end marker is missing.
+	if r.inKey {
+		if loc := pemKeyEnd.FindIndex(line); loc != nil {
+			r.inKey = false
+			return append(redacted(RuleID("Private Key")), line[loc[0]:]...)
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			return line
+		}
+		r.keyLines++
+		if r.keyLines <= maxPEMKeyLines && pemKeyBody.Match(line) {
+			return redacted(RuleID("Private Key"))
+		}
+		r.inKey = false
+	}
+
+	// Collect what to mask, starting with private keys from their begin
+	// marker to their end marker or the end of the line
+	var spans []redactSpan
+	for _, begin := range pemKeyBegin.FindAllIndex(line, -1) {
+		span := redactSpan{begin[0], len(line), RuleID("Private Key")}
+		if end := pemKeyEnd.FindIndex(line[begin[1]:]); end != nil {
+			span.end = begin[1] + end[1]
+		} else {
+			r.inKey, r.keyLines = true, 0
+		}
+		spans = append(spans, span)
+	}
+	spans = append(spans, r.matchSpans(line)...)
+	if len(spans) == 0 {
+		return line
+	}
+
+	// Overlapping matches are masked as one, under the rule that starts first
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var out []byte
+	pos := 0
+	for i := 0; i < len(spans); i++ {
+		span := spans[i]
+		for i+1 < len(spans) && spans[i+1].start < span.end {
+			span.end = max(span.end, spans[i+1].end)
+			i++
+		}
+		out = append(out, line[pos:span.start]...)
+		out = append(out, redacted(span.rule)...)
+		pos = span.end
+	}
+	return append(out, line[pos:]...)
+}
+
+// matchSpans returns the secret of each pattern match in a line, or the
+// whole match when the pattern does not capture it, and every occurrence of
+// the literal values
+func (r *Redactor) matchSpans(line []byte) []redactSpan {
+	var spans []redactSpan
+	for _, name := range r.rules {
+		for _, loc := range Patterns[name].FindAllSubmatchIndex(line, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			spans = append(spans, redactSpan{start, end, RuleID(name)})
+		}
+	}
+	for _, literal := range r.literals {
+		for offset := 0; ; {
+			i := bytes.Index(line[offset:], literal)
+			if i < 0 {
+				break
+			}
+			spans = append(spans, redactSpan{offset + i, offset + i + len(literal), LiteralRule})
+			offset += i + len(literal)
+		}
+	}
+	return spans
+}
+
+// redactSpan is a part of a line to mask
+type redactSpan struct {
+	start, end int
+	rule       string
+}
+
+// redacted returns the replacement for a masked secret
+func redacted(rule string) []byte {
+	return []byte("***[" + rule + "]***")
+}
+
+// sortedPatternNames returns the names of Patterns in a fixed order, so that
+// overlapping matches are always attributed to the same rule
+func sortedPatternNames() []string {
+	names := make([]string, 0, len(Patterns))
+	for name := range Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
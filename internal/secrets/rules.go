@@ -0,0 +1,43 @@
+package secrets
+
+import "sort"
+
+// Rule describes one of the rules findings can be reported under
+type Rule struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Severity Severity `json:"severity"`
+	Version  int      `json:"version"`
+}
+
+// RuleVersions records the version of rules whose detection logic has
+// changed in a way that affects results. Bump a rule's version whenever its
+// pattern or detector changes what it reports; rules not listed are at
+// version 1.
+var RuleVersions = map[string]int{}
+
+// Rules returns every rule known to the scanner, sorted by ID: the regex
+// Patterns, the rules reported by Detectors and the CompositeRules
+func Rules() []Rule {
+	names := make(map[string]bool)
+	for name := range Patterns {
+		names[name] = true
+	}
+	for name := range Severities {
+		names[name] = true
+	}
+	for _, rule := range CompositeRules {
+		names[rule.Name] = true
+	}
+
+	rules := make([]Rule, 0, len(names))
+	for name := range names {
+		version := RuleVersions[name]
+		if version == 0 {
+			version = 1
+		}
+		rules = append(rules, Rule{ID: RuleID(name), Name: name, Severity: SeverityFor(name), Version: version})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
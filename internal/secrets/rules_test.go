@@ -0,0 +1,27 @@
+package secrets
+
+import "testing"
+
+func TestRules(t *testing.T) {
+	rules := Rules()
+
+	seen := make(map[string]Rule)
+	for i, rule := range rules {
+		if i > 0 && rules[i-1].ID >= rule.ID {
+			t.Errorf("Rules are not sorted by ID: %s before %s", rules[i-1].ID, rule.ID)
+		}
+		if rule.Version < 1 {
+			t.Errorf("Rule %s has version %d", rule.ID, rule.Version)
+		}
+		seen[rule.Name] = rule
+	}
+
+	for _, name := range []string{"AWS Access Key", "Kubernetes Secret", "AWS Credential Pair"} {
+		if _, ok := seen[name]; !ok {
+			t.Errorf("Expected %s among the rules", name)
+		}
+	}
+	if seen["Private Key"].Severity != SeverityCritical {
+		t.Errorf("Expected Private Key to be critical, got %s", seen["Private Key"].Severity)
+	}
+}
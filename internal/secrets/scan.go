@@ -3,95 +3,360 @@ package secrets
 import (
 	"bufio"
 	"bytes"
-	"os"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 )
 
 // Finding represents a detected credential leak in a file
 type Finding struct {
-	File     string // Path to the file containing the leak
-	Rule     string // Name of the pattern rule that matched
-	LineNum  int    // Line number where the leak was found
-	LineText string // Content of the line (potentially truncated for display)
-	Offset   []int  // Start and end position of the match in the file content
+	File     string     `json:"file"`               // Path to the file containing the leak
+	Rule     string     `json:"rule"`               // Name of the pattern rule that matched
+	Severity Severity   `json:"severity"`           // How damaging the leak is likely to be
+	LineNum  int        `json:"line"`               // Line number where the leak was found
+	EndLine  int        `json:"end_line,omitempty"` // Last line of a secret spanning several lines, otherwise 0
+	Column   int        `json:"column"`             // Column (1-based, in bytes) where the match starts
+	LineText string     `json:"line_text"`          // Content of the line (potentially truncated for display)
+	Offset   []int      `json:"offset"`             // Start and end position of the match in the file content
+	Key      string     `json:"key,omitempty"`      // Name of the secret within a structured file, if known
+	Related  []Location `json:"related,omitempty"`  // Matches correlated into this finding by a composite rule
+
+	Verification string `json:"verification,omitempty"` // Result of live verification (verified-active, revoked or unknown), empty when not verified
+	Suppression  string `json:"-"`                      // Why the finding was accepted (SuppressionInline or SuppressionBaseline), empty when it was not
+	Secret       string `json:"-"`                      // The matched secret value, never written to reports
+}
+
+// Fingerprint returns a stable identifier for a finding. It is derived from
+// the rule, the file and the secret rather than the line number, so it
+// survives unrelated edits that move the secret around the file.
+func (f Finding) Fingerprint() string {
+	value := f.Secret
+	if value == "" {
+		value = f.Key
+	}
+	if value == "" {
+		value = strings.TrimSpace(f.LineText)
+	}
+
+	sum := sha256.Sum256([]byte(RuleID(f.Rule) + "\x00" + filepath.ToSlash(f.File) + "\x00" + value))
+	return hex.EncodeToString(sum[:])
+}
+
+// Location points at one of the matches behind a correlated finding
+type Location struct {
+	File    string `json:"file"` // Path to the file containing the match
+	Rule    string `json:"rule"` // Name of the rule that matched
+	LineNum int    `json:"line"` // Line number of the match
+}
+
+// Detector finds secrets that can only be recognized by understanding the
+// structure of a file, such as a Kubernetes manifest. It returns nil for
+// files it does not understand.
+type Detector func(path string, content []byte) []Finding
+
+// Detectors contains the structure-aware detectors that run on every scanned
+// file alongside Patterns.
+var Detectors = map[string]Detector{
+	"Kubernetes":       detectKubernetes,
+	"Terraform":        detectTerraform,
+	"Credential Files": detectCredentialFiles,
+	"Multi-line":       detectMultiline,
+}
+
+// detectorOrder is the order in which the built-in Detectors run. When the
+// findings of two detectors cover the same lines, only the first detector's
+// finding is reported, so the detectors that know where a secret is used come
+// before the one that only recognizes its shape. Other detectors run after
+// them in name order.
+var detectorOrder = []string{"Kubernetes", "Terraform", "Credential Files", "Multi-line"}
+
+// sortedDetectorNames returns the names of Detectors in the order they run
+func sortedDetectorNames() []string {
+	names := make([]string, 0, len(Detectors))
+	for name := range Detectors {
+		names = append(names, name)
+	}
+	rank := func(name string) int {
+		if i := slices.Index(detectorOrder, name); i >= 0 {
+			return i
+		}
+		return len(detectorOrder)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if rank(names[i]) != rank(names[j]) {
+			return rank(names[i]) < rank(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Reasons for skipping a file
+const (
+	SkipIgnored    = "ignored"    // Matched an ignore pattern
+	SkipTooLarge   = "too-large"  // Larger than MaxFileSize
+	SkipBinary     = "binary"     // Looks like a binary file
+	SkipEncrypted  = "encrypted"  // Encrypted as a whole with git-crypt or Ansible Vault
+	SkipUnreadable = "unreadable" // Could not be read
+)
+
+// MaxFileSize is the size above which files are skipped
+const MaxFileSize = 5 * 1024 * 1024
+
+// Operations that can fail during a scan
+const (
+	OpStat = "stat" // Getting information about a path
+	OpList = "list" // Listing the contents of a directory
+	OpRead = "read" // Reading the content of a file
+)
+
+// ScanError records a path that could not be scanned
+type ScanError struct {
+	Path string // Path that could not be scanned
+	Op   string // Operation that failed: OpStat, OpList or OpRead
+	Err  error  // Underlying error
+}
+
+// Error implements the error interface
+func (e ScanError) Error() string {
+	return e.Op + " " + e.Path + ": " + errors.Unwrap(e).Error()
+}
+
+// Unwrap returns the underlying error, without the path and operation that
+// os errors repeat
+func (e ScanError) Unwrap() error {
+	var pathErr *fs.PathError
+	if errors.As(e.Err, &pathErr) {
+		return pathErr.Err
+	}
+	return e.Err
+}
+
+// Stats describes how much of a directory a scan covered
+type Stats struct {
+	FilesVisited int                      // Files found by the walk, scanned or not
+	FilesScanned int                      // Files whose content was scanned
+	BytesScanned int64                    // Total size of the scanned files
+	Skipped      map[string]int           // Files skipped, by reason. Ignored directories count once under SkipIgnored.
+	Errors       []ScanError              // Files and directories that could not be read
+	Duration     time.Duration            // Wall time of the scan
+	RuleTime     map[string]time.Duration // CPU time spent in each pattern and detector, by rule or detector name
+}
+
+// skip records a skipped file
+func (s *Stats) skip(reason string) {
+	if s.Skipped == nil {
+		s.Skipped = make(map[string]int)
+	}
+	s.Skipped[reason]++
 }
 
 // ScanDir recursively scans a directory for credential leaks
 func ScanDir(rootPath string, ignorePatterns []string) ([]Finding, error) {
+	findings, _, err := ScanDirWithStats(rootPath, ignorePatterns)
+	return findings, err
+}
+
+// ScanDirWithStats recursively scans a directory for credential leaks and
+// reports which files were scanned and which were skipped
+func ScanDirWithStats(rootPath string, ignorePatterns []string) ([]Finding, Stats, error) {
 	var findings []Finding
+	stats, err := ScanDirFunc(rootPath, ignorePatterns, func(f Finding) {
+		findings = append(findings, f)
+	})
+	return findings, stats, err
+}
+
+// ScanDirFunc recursively scans a directory for credential leaks and calls fn
+// with each finding as soon as the file containing it has been scanned, so
+// that callers can stream results without holding them all in memory
+func ScanDirFunc(rootPath string, ignorePatterns []string, fn func(Finding)) (Stats, error) {
+	return ScanPathsFunc([]string{rootPath}, ignorePatterns, fn)
+}
 
-	// Walk through all files in the directory
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+// ScanPathsFunc scans several files and directories, such as a list of
+// files selected by another tool, and calls fn with each finding. Each path
+// is walked exactly as ScanDir walks its root, so ignore patterns apply to
+// files given explicitly too.
+func ScanPathsFunc(paths []string, ignorePatterns []string, fn func(Finding)) (Stats, error) {
+	stats := Stats{RuleTime: make(map[string]time.Duration)}
+	started := time.Now()
+
+	for _, rootPath := range paths {
+		policy := newEncryptionPolicy(rootPath)
+		err := walkDir(rootPath, ignorePatterns, &stats, func(d FileDecision, content []byte) {
+			if d.Skip == "" {
+				scanFile(d.Path, content, policy, stats.RuleTime, fn)
+			}
+		})
 		if err != nil {
-			return nil // Skip files with errors
+			stats.Duration = time.Since(started)
+			return stats, err
 		}
+	}
 
-		// Skip directories
-		if info.IsDir() {
-			// Check if directory should be ignored
-			for _, pattern := range ignorePatterns {
-				if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
-					return filepath.SkipDir
-				}
-			}
-			return nil
+	stats.Duration = time.Since(started)
+	return stats, nil
+}
+
+// ScanReader scans content read from r, such as stdin, as if it were a file
+// called name. Rules that look at file names see name, and findings are
+// reported in it.
+func ScanReader(name string, r io.Reader) ([]Finding, error) {
+	var findings []Finding
+	_, err := ScanReaderFunc(name, r, func(f Finding) {
+		findings = append(findings, f)
+	})
+	return findings, err
+}
+
+// ScanReaderFunc scans content read from r like ScanReader and calls fn with
+// each finding. Unlike files found by a walk, the content is scanned
+// whatever its size.
+func ScanReaderFunc(name string, r io.Reader, fn func(Finding)) (Stats, error) {
+	stats := Stats{RuleTime: make(map[string]time.Duration), FilesVisited: 1}
+	started := time.Now()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		stats.skip(SkipUnreadable)
+		stats.Duration = time.Since(started)
+		return stats, ScanError{Path: name, Op: OpRead, Err: err}
+	}
+
+	switch {
+	case fileEncryptionFormat(content) != "":
+		stats.skip(SkipEncrypted)
+	case isBinary(content):
+		stats.skip(SkipBinary)
+	default:
+		stats.FilesScanned++
+		stats.BytesScanned += int64(len(content))
+		scanFile(name, content, newEncryptionPolicy(filepath.Dir(name)), stats.RuleTime, fn)
+	}
+	stats.Duration = time.Since(started)
+	return stats, nil
+}
+
+// scanFile calls fn with each finding in the content of a file
+func scanFile(path string, content []byte, policy *encryptionPolicy, ruleTime map[string]time.Duration, fn func(Finding)) {
+	// Files that must be encrypted should never be committed in plaintext
+	if policy.requiresEncryption(path) && encryptionFormat(content) == "" {
+		fn(unencryptedFinding(path, content))
+	}
+
+	for _, f := range scanContent(path, content, ruleTime) {
+		fn(f)
+	}
+}
+
+// scanContent checks the content of a single file against Detectors and
+// Patterns, then correlates the results with CompositeRules. The CPU time
+// spent in each detector and pattern is added to ruleTime unless it is nil.
+func scanContent(path string, content []byte, ruleTime map[string]time.Duration) []Finding {
+	var findings []Finding
+
+	// The thread CPU clock only measures this goroutine while it stays on
+	// one thread
+	if ruleTime != nil {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+
+	// Map to keep track of lines where findings were already reported.
+	// Lines inside inline encrypted values are treated as reported so
+	// that nothing inside them is flagged.
+	reportedLines := encryptedLines(content)
+
+	// Structured findings are more precise than a regex hit on the same
+	// line, so they take precedence. A detector may report several values
+	// on one line, but not a secret that an earlier detector reported.
+	for _, name := range sortedDetectorNames() {
+		started := threadCPUTime()
+		detected := Detectors[name](path, content)
+		if ruleTime != nil {
+			ruleTime[name] += threadCPUTime() - started
 		}
 
-		// Check if file should be ignored
-		for _, pattern := range ignorePatterns {
-			if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
-				return nil
+		var claimed []int
+		for _, f := range detected {
+			lines := make([]int, 0, max(f.LineNum, f.EndLine)-f.LineNum+1)
+			for line := f.LineNum; line <= max(f.LineNum, f.EndLine); line++ {
+				lines = append(lines, line)
 			}
-		}
+			if slices.ContainsFunc(lines, func(line int) bool { return reportedLines[line] }) {
+				continue
+			}
+			claimed = append(claimed, lines...)
 
-		// Skip binary files and large files
-		if info.Size() > 1024*1024*5 { // 5MB limit
-			return nil
+			if f.Severity == "" {
+				f.Severity = SeverityFor(f.Rule)
+			}
+			findings = append(findings, f)
 		}
-
-		// Read file content
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return nil
+		for _, line := range claimed {
+			reportedLines[line] = true
 		}
+	}
 
-		// Skip if likely binary file
-		if isBinary(content) {
-			return nil
+	// Check content against each pattern
+	for _, ruleName := range sortedPatternNames() {
+		pattern := Patterns[ruleName]
+		started := threadCPUTime()
+		matches := pattern.FindAllSubmatchIndex(content, -1)
+		if ruleTime != nil {
+			ruleTime[ruleName] += threadCPUTime() - started
 		}
+		for _, loc := range matches {
+			// Get line number for this match
+			lineNum, lineText := getLineInfo(content, loc[0])
 
-		// Map to keep track of lines where findings were already reported
-		reportedLines := make(map[int]bool)
+			// Skip if we already reported an issue on this line
+			if reportedLines[lineNum] {
+				continue
+			}
+			reportedLines[lineNum] = true
 
-		// Check content against each pattern
-		for ruleName, pattern := range Patterns {
-			matches := pattern.FindAllIndex(content, -1)
-			for _, loc := range matches {
-				// Get line number for this match
-				lineNum, lineText := getLineInfo(content, loc[0])
+			// Create a finding
+			findings = append(findings, Finding{
+				File:     path,
+				Rule:     ruleName,
+				Severity: SeverityFor(ruleName),
+				LineNum:  lineNum,
+				LineText: sanitizeLineText(lineText),
+				Offset:   loc[:2],
+				Secret:   matchedSecret(content, loc),
+			})
+		}
+	}
 
-				// Skip if we already reported an issue on this line
-				if reportedLines[lineNum] {
-					continue
-				}
-				reportedLines[lineNum] = true
-
-				// Create a finding
-				findings = append(findings, Finding{
-					File:     path,
-					Rule:     ruleName,
-					LineNum:  lineNum,
-					LineText: sanitizeLineText(lineText),
-					Offset:   loc,
-				})
-			}
+	// Locate the column of each match within its line
+	for i := range findings {
+		if findings[i].Column == 0 && len(findings[i].Offset) > 0 {
+			findings[i].Column = getColumn(content, findings[i].Offset[0])
 		}
+	}
 
-		return nil
-	})
+	findings = correlate(findings)
+	suppressInline(content, findings)
+	return findings
+}
 
-	return findings, err
+// matchedSecret returns the secret captured by a pattern match: the first
+// capture group when the pattern has one, otherwise the whole match
+func matchedSecret(content []byte, loc []int) string {
+	if len(loc) >= 4 && loc[2] >= 0 {
+		return string(content[loc[2]:loc[3]])
+	}
+	return string(content[loc[0]:loc[1]])
 }
 
 // isBinary does a simple check if a file appears to be binary
@@ -133,6 +398,40 @@ func getLineInfo(content []byte, position int) (int, string) {
 	return lineNum, ""
 }
 
+// getLineText returns the text of a 1-based line number in a file
+func getLineText(content []byte, lineNum int) string {
+	lines := bytes.Split(content, []byte("\n"))
+	if lineNum < 1 || lineNum > len(lines) {
+		return ""
+	}
+	return strings.TrimRight(string(lines[lineNum-1]), "\r")
+}
+
+// getColumn returns the 1-based column of a position in a file
+func getColumn(content []byte, position int) int {
+	if position > len(content) {
+		position = len(content)
+	}
+	return position - bytes.LastIndexByte(content[:position], '\n')
+}
+
+// getPosition converts a 1-based line and column into a byte offset
+func getPosition(content []byte, lineNum, column int) int {
+	pos := 0
+	for line := 1; line < lineNum && pos < len(content); line++ {
+		next := bytes.IndexByte(content[pos:], '\n')
+		if next < 0 {
+			return len(content)
+		}
+		pos += next + 1
+	}
+	pos += column - 1
+	if pos > len(content) {
+		pos = len(content)
+	}
+	return pos
+}
+
 // sanitizeLineText removes sensitive data and truncates if needed
 func sanitizeLineText(text string) string {
 	// Truncate long lines
@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity describes how damaging a leaked secret is likely to be
+type Severity string
+
+// Supported severity levels, from least to most severe
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// severityRanks orders the severity levels for comparisons
+var severityRanks = map[Severity]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// Severities assigns a severity to each rule name. Composite rules carry
+// their own severity in CompositeRules, and other rules without an entry are
+// reported as SeverityMedium.
+var Severities = map[string]Severity{
+	// Cloud Provider Credentials
+	"AWS Access Key":          SeverityMedium,
+	"AWS Secret Key":          SeverityHigh,
+	"Azure Connection String": SeverityHigh,
+	"Google API Key":          SeverityMedium,
+
+	// Version Control & CI/CD Tokens
+	"GitHub Token": SeverityHigh,
+	"GitLab Token": SeverityHigh,
+	"NPM Token":    SeverityHigh,
+
+	// Chat & Collaboration Tokens
+	"Slack Token": SeverityHigh,
+
+	// Generic Credentials
+	"Generic API Key":     SeverityMedium,
+	"Private Key":         SeverityCritical,
+	"Password Assignment": SeverityMedium,
+
+	// Database Credentials
+	"Connection String": SeverityHigh,
+	"Database Password": SeverityHigh,
+
+	// Authentication Tokens
+	"JWT Token":             SeverityMedium,
+	"SSH URL with password": SeverityMedium,
+	"Basic Auth":            SeverityHigh,
+	"Bearer Token":          SeverityHigh,
+
+	// Structured Detectors
+	"Kubernetes Secret":         SeverityHigh,
+	"Kubeconfig Credential":     SeverityHigh,
+	"Unencrypted Secret File":   SeverityHigh,
+	"Terraform Sensitive Value": SeverityHigh,
+	"GCP Service Account Key":   SeverityCritical,
+
+	// Package Manager & Tool Credential Files
+	"NPM Registry Credential":    SeverityHigh,
+	"PyPI Credential":            SeverityHigh,
+	"Docker Registry Credential": SeverityHigh,
+	"Netrc Credential":           SeverityHigh,
+	"Maven Server Credential":    SeverityHigh,
+
+	// Environment Variables
+	"Secret Environment Variable":    SeverityMedium,
+	"Forbidden Environment Variable": SeverityHigh,
+}
+
+// ParseSeverity converts a severity name into a Severity
+func ParseSeverity(name string) (Severity, error) {
+	s := Severity(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := severityRanks[s]; !ok {
+		return "", fmt.Errorf("unknown severity %q (expected low, medium, high or critical)", name)
+	}
+	return s, nil
+}
+
+// AtLeast reports whether s is as severe as or more severe than other
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRanks[s] >= severityRanks[other]
+}
+
+// SeverityFor returns the configured severity of a rule
+func SeverityFor(rule string) Severity {
+	if s, ok := Severities[rule]; ok {
+		return s
+	}
+	for _, composite := range CompositeRules {
+		if composite.Name == rule && composite.Severity != "" {
+			return composite.Severity
+		}
+	}
+	return SeverityMedium
+}
@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Reasons a finding is suppressed
+const (
+	SuppressionInline   = "inline"   // Its line carries a pipeline-guardian:ignore comment
+	SuppressionBaseline = "baseline" // It was accepted in a baseline of earlier findings
+)
+
+// ignoreComment matches an inline ignore comment, optionally limited to a
+// comma-separated list of rule IDs, e.g.
+// "# pipeline-guardian:ignore=aws-access-key,generic-api-key"
+var ignoreComment = regexp.MustCompile(`pipeline-guardian:ignore(?:=([a-z0-9,-]+))?`)
+
+// suppressInline marks findings whose first line carries an ignore comment
+// that covers their rule. The full line is checked, since LineText may be
+// truncated before the comment.
+func suppressInline(content []byte, findings []Finding) {
+	if !strings.Contains(string(content), "pipeline-guardian:ignore") {
+		return
+	}
+
+	for i := range findings {
+		m := ignoreComment.FindStringSubmatch(getLineText(content, findings[i].LineNum))
+		if m == nil {
+			continue
+		}
+		if m[1] == "" || slices.Contains(strings.Split(m[1], ","), RuleID(findings[i].Rule)) {
+			findings[i].Suppression = SuppressionInline
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// sensitiveName matches variable and attribute names that usually hold
+// credentials
+var sensitiveName = regexp.MustCompile(`(?i)password|passwd|secret|token|api_?key|access_?key|private_?key|credential`)
+
+// detectTerraform reports sensitive values in Terraform state files, variable
+// files and variable defaults. Findings are located by Terraform address
+// (e.g. aws_db_instance.main.password) rather than by the raw line text.
+func detectTerraform(path string, content []byte) []Finding {
+	name := filepath.Base(path)
+	switch {
+	case strings.HasSuffix(name, ".tfstate") || strings.HasSuffix(name, ".tfstate.backup"):
+		return terraformStateFindings(path, content)
+	case strings.HasSuffix(name, ".tfvars") || strings.HasSuffix(name, ".tfvars.json"):
+		return terraformVarsFindings(path, content)
+	case strings.HasSuffix(name, ".tf"):
+		return terraformVariableFindings(path, content)
+	}
+	return nil
+}
+
+// terraformState is the subset of the v4 state format that can hold secrets
+type terraformState struct {
+	Version int `json:"version"`
+	Outputs map[string]struct {
+		Value     interface{} `json:"value"`
+		Sensitive bool        `json:"sensitive"`
+	} `json:"outputs"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey            interface{}            `json:"index_key"`
+			Attributes          map[string]interface{} `json:"attributes"`
+			SensitiveAttributes []json.RawMessage      `json:"sensitive_attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// terraformPathStep is one step of a sensitive attribute path in state
+type terraformPathStep struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// terraformStateFindings reports sensitive outputs and resource attributes
+// of a v4 state file
+func terraformStateFindings(path string, content []byte) []Finding {
+	var state terraformState
+	if err := json.Unmarshal(content, &state); err != nil || state.Version != 4 {
+		return nil
+	}
+
+	spans := jsonValueSpans(content)
+
+	var findings []Finding
+	names := make([]string, 0, len(state.Outputs))
+	for name := range state.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if output := state.Outputs[name]; output.Sensitive && !isEmptyValue(output.Value) {
+			span := spans[jsonPath("outputs", name, "value")]
+			findings = append(findings, terraformFinding(path, content, "output."+name, span))
+		}
+	}
+
+	for r, resource := range state.Resources {
+		address := resource.Type + "." + resource.Name
+		if resource.Mode == "data" {
+			address = "data." + address
+		}
+		if resource.Module != "" {
+			address = resource.Module + "." + address
+		}
+
+		for i, instance := range resource.Instances {
+			instanceAddress := address
+			switch key := instance.IndexKey.(type) {
+			case float64:
+				instanceAddress += fmt.Sprintf("[%d]", int(key))
+			case string:
+				instanceAddress += fmt.Sprintf("[%q]", key)
+			}
+
+			for _, raw := range instance.SensitiveAttributes {
+				var steps []terraformPathStep
+				if err := json.Unmarshal(raw, &steps); err != nil {
+					continue
+				}
+				parts, value := resolveStatePath(instance.Attributes, steps)
+				if len(parts) == 0 || isEmptyValue(value) {
+					continue
+				}
+				span := spans[jsonPath(append([]string{"resources", strconv.Itoa(r), "instances", strconv.Itoa(i), "attributes"}, parts...)...)]
+				findings = append(findings, terraformFinding(path, content, instanceAddress+"."+strings.Join(parts, "."), span))
+			}
+		}
+	}
+
+	return findings
+}
+
+// resolveStatePath follows a sensitive attribute path through the attributes
+// of a resource instance, returning the attribute names and list indexes on
+// the path and the value found
+func resolveStatePath(attributes map[string]interface{}, steps []terraformPathStep) ([]string, interface{}) {
+	var parts []string
+	var current interface{} = attributes
+
+	for _, step := range steps {
+		switch step.Type {
+		case "get_attr":
+			var name string
+			if json.Unmarshal(step.Value, &name) != nil {
+				return nil, nil
+			}
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, nil
+			}
+			current = object[name]
+			parts = append(parts, name)
+
+		case "index":
+			var index struct {
+				Value interface{} `json:"value"`
+			}
+			if json.Unmarshal(step.Value, &index) != nil {
+				return nil, nil
+			}
+			switch key := index.Value.(type) {
+			case float64:
+				list, ok := current.([]interface{})
+				if !ok || int(key) >= len(list) {
+					return nil, nil
+				}
+				current = list[int(key)]
+				parts = append(parts, fmt.Sprintf("%d", int(key)))
+			case string:
+				object, ok := current.(map[string]interface{})
+				if !ok {
+					return nil, nil
+				}
+				current = object[key]
+				parts = append(parts, key)
+			}
+
+		default:
+			return nil, nil
+		}
+	}
+
+	return parts, current
+}
+
+// terraformVarsFindings reports variable values with sensitive names in a
+// .tfvars or .tfvars.json file
+func terraformVarsFindings(path string, content []byte) []Finding {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, ".json") {
+		file, diags = hcljson.Parse(content, path)
+	} else {
+		file, diags = hclsyntax.ParseConfig(content, path, hcl.Pos{Line: 1, Column: 1})
+	}
+	if diags.HasErrors() {
+		return nil
+	}
+
+	attributes, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []Finding
+	for _, name := range names {
+		findings = append(findings, terraformExpressionFindings(path, content, "var."+name, name, attributes[name].Expr, false)...)
+	}
+	return findings
+}
+
+// terraformVariableFindings reports the defaults of variable blocks that are
+// marked sensitive or have sensitive names
+func terraformVariableFindings(path string, content []byte) []Finding {
+	if !strings.Contains(string(content), "variable") {
+		return nil
+	}
+
+	file, diags := hclsyntax.ParseConfig(content, path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	var findings []Finding
+	for _, block := range body.Blocks {
+		if block.Type != "variable" || len(block.Labels) != 1 {
+			continue
+		}
+		defaultValue, ok := block.Body.Attributes["default"]
+		if !ok {
+			continue
+		}
+
+		sensitive := false
+		if attribute, ok := block.Body.Attributes["sensitive"]; ok {
+			if value, diags := attribute.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.Bool && value.IsKnown() && !value.IsNull() {
+				sensitive = value.True()
+			}
+		}
+
+		name := block.Labels[0]
+		findings = append(findings, terraformExpressionFindings(path, content, "var."+name, name, defaultValue.Expr, sensitive)...)
+	}
+	return findings
+}
+
+// terraformExpressionFindings reports the literal string values of an
+// expression that are sensitive, either because the whole value is marked
+// sensitive or because their name suggests a credential. Objects, maps,
+// lists and tuples are walked, and each value is located at its own
+// position. Expressions that reference variables or functions, such as
+// "${var.password}", are skipped.
+func terraformExpressionFindings(path string, content []byte, address, name string, expr hcl.Expression, sensitive bool) []Finding {
+	var findings []Finding
+
+	// Native syntax keeps the range of every nested expression
+	var walkSyntax func(address, name string, expr hclsyntax.Expression, sensitive bool)
+	walkSyntax = func(address, name string, expr hclsyntax.Expression, sensitive bool) {
+		sensitive = sensitive || sensitiveName.MatchString(name)
+
+		switch e := expr.(type) {
+		case *hclsyntax.ObjectConsExpr:
+			for _, item := range e.Items {
+				key, diags := item.KeyExpr.Value(nil)
+				if diags.HasErrors() || key.Type() != cty.String || !key.IsKnown() || key.IsNull() {
+					continue
+				}
+				walkSyntax(address+"."+key.AsString(), key.AsString(), item.ValueExpr, sensitive)
+			}
+		case *hclsyntax.TupleConsExpr:
+			for i, element := range e.Exprs {
+				walkSyntax(address+"."+strconv.Itoa(i), name, element, sensitive)
+			}
+		default:
+			value, diags := e.Value(nil)
+			if diags.HasErrors() || !sensitive || !isNonEmptyString(value) {
+				return
+			}
+			r := e.Range()
+			findings = append(findings, terraformFinding(path, content, address, [2]int{r.Start.Byte, r.End.Byte}))
+		}
+	}
+	if native, ok := expr.(hclsyntax.Expression); ok {
+		walkSyntax(address, name, native, sensitive)
+		return findings
+	}
+
+	// JSON expressions only expose their value, so nested values are
+	// located in the document by their path
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return nil
+	}
+	spans := jsonValueSpans(content)
+	r := expr.Range()
+
+	var walkValue func(address, name string, steps []string, value cty.Value, sensitive bool)
+	walkValue = func(address, name string, steps []string, value cty.Value, sensitive bool) {
+		if value.IsNull() || !value.IsKnown() {
+			return
+		}
+		sensitive = sensitive || sensitiveName.MatchString(name)
+
+		switch {
+		case value.Type() == cty.String:
+			if !sensitive || value.AsString() == "" {
+				return
+			}
+			span, ok := spans[jsonPath(steps...)]
+			if !ok {
+				span = [2]int{r.Start.Byte, r.End.Byte}
+			}
+			findings = append(findings, terraformFinding(path, content, address, span))
+		case value.Type().IsObjectType() || value.Type().IsMapType():
+			keys := make([]string, 0, value.LengthInt())
+			elements := value.AsValueMap()
+			for key := range elements {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walkValue(address+"."+key, key, append(steps, key), elements[key], sensitive)
+			}
+		case value.Type().IsListType() || value.Type().IsTupleType():
+			for i, element := range value.AsValueSlice() {
+				walkValue(address+"."+strconv.Itoa(i), name, append(steps, strconv.Itoa(i)), element, sensitive)
+			}
+		}
+	}
+	walkValue(address, name, []string{name}, value, sensitive)
+
+	return findings
+}
+
+// isNonEmptyString reports whether a value is a known string with content
+func isNonEmptyString(value cty.Value) bool {
+	return value.Type() == cty.String && value.IsKnown() && !value.IsNull() && value.AsString() != ""
+}
+
+// terraformFinding builds a finding for the value at span in a state or
+// variables file. String values are located without their quotes.
+func terraformFinding(path string, content []byte, address string, span [2]int) Finding {
+	start, end := span[0], span[1]
+	if end-start >= 2 && content[start] == '"' && content[end-1] == '"' {
+		start, end = start+1, end-1
+	}
+	lineNum, _ := getLineInfo(content, start)
+
+	return Finding{
+		File:     path,
+		Rule:     "Terraform Sensitive Value",
+		LineNum:  lineNum,
+		LineText: address,
+		Offset:   []int{start, end},
+		Key:      address,
+		Secret:   string(content[start:end]),
+	}
+}
+
+// jsonPath joins object keys and array indexes into a key of jsonValueSpans
+func jsonPath(steps ...string) string {
+	return strings.Join(steps, "\x00")
+}
+
+// jsonValueSpans returns the start and end offsets of every value in a JSON
+// document, keyed by the jsonPath of object keys and array indexes leading
+// to it. Offsets of a malformed document are returned up to the error.
+func jsonValueSpans(content []byte) map[string][2]int {
+	spans := make(map[string][2]int)
+	decoder := json.NewDecoder(bytes.NewReader(content))
+
+	var walk func(steps []string) error
+	walk = func(steps []string) error {
+		// The decoder's offset is at the end of the previous token, before
+		// any separator and white space
+		start := int(decoder.InputOffset())
+		for start < len(content) && strings.IndexByte(" \t\r\n,:", content[start]) >= 0 {
+			start++
+		}
+
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'):
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				name, _ := key.(string)
+				if err := walk(append(steps, name)); err != nil {
+					return err
+				}
+			}
+			if _, err := decoder.Token(); err != nil {
+				return err
+			}
+		case json.Delim('['):
+			for i := 0; decoder.More(); i++ {
+				if err := walk(append(steps, strconv.Itoa(i))); err != nil {
+					return err
+				}
+			}
+			if _, err := decoder.Token(); err != nil {
+				return err
+			}
+		}
+
+		spans[jsonPath(steps...)] = [2]int{start, int(decoder.InputOffset())}
+		return nil
+	}
+	_ = walk(nil)
+
+	return spans
+}
+
+// isEmptyValue reports whether a decoded JSON value holds nothing worth
+// reporting
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+package secrets
+
+import (
+	"testing"
+)
+
+func TestDetectTerraformState(t *testing.T) {
+	state := []byte(`{
+  "version": 4,
+  "terraform_version": "1.7.5",
+  "outputs": {
+    "db_endpoint": {
+      "value": "main.abc123.us-east-1.rds.amazonaws.com",
+      "type": "string"
+    },
+    "db_password": {
+      "value": "output-secret-value",
+      "type": "string",
+      "sensitive": true
+    }
+  },
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_db_instance",
+      "name": "main",
+      "instances": [
+        {
+          "attributes": {
+            "identifier": "main",
+            "password": "s3cr3t-db-password",
+            "username": "admin"
+          },
+          "sensitive_attributes": [
+            [{"type": "get_attr", "value": "password"}]
+          ]
+        }
+      ]
+    },
+    {
+      "module": "module.cache",
+      "mode": "managed",
+      "type": "aws_elasticache_user",
+      "name": "app",
+      "instances": [
+        {
+          "index_key": 0,
+          "attributes": {
+            "passwords": ["cache-password-1"]
+          },
+          "sensitive_attributes": [
+            [{"type": "get_attr", "value": "passwords"}, {"type": "index", "value": {"value": 0, "type": "number"}}]
+          ]
+        }
+      ]
+    }
+  ]
+}`)
+
+	findings := detectTerraform("terraform.tfstate", state)
+
+	expected := map[string]int{
+		"output.db_password":                                   10,
+		"aws_db_instance.main.password":                        24,
+		"module.cache.aws_elasticache_user.app[0].passwords.0": 42,
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %d: %+v", len(expected), len(findings), findings)
+	}
+	for _, f := range findings {
+		line, ok := expected[f.Key]
+		if !ok {
+			t.Errorf("Unexpected finding for address %q", f.Key)
+			continue
+		}
+		if f.LineNum != line {
+			t.Errorf("Expected %s on line %d, got %d", f.Key, line, f.LineNum)
+		}
+		if f.LineText != f.Key {
+			t.Errorf("Expected line text to be the address %q, got %q", f.Key, f.LineText)
+		}
+	}
+}
+
+func TestDetectTerraformVariables(t *testing.T) {
+	tfvars := []byte(`region      = "us-east-1"
+db_password = "hunter2hunter2"
+api_token   = "${var.injected_token}"
+provider = {
+  client_secret = "provider-client-secret"
+  client_id     = "public-client-id"
+}
+`)
+
+	findings := detectTerraform("prod.tfvars", tfvars)
+	keys := map[string]int{}
+	for _, f := range findings {
+		keys[f.Key] = f.LineNum
+	}
+	if len(findings) != 2 {
+		t.Errorf("Expected 2 findings, got %d: %+v", len(findings), findings)
+	}
+	if keys["var.db_password"] != 2 {
+		t.Errorf("Expected var.db_password on line 2, got %+v", keys)
+	}
+	if _, ok := keys["var.provider.client_secret"]; !ok {
+		t.Errorf("Expected var.provider.client_secret, got %+v", keys)
+	}
+
+	variables := []byte(`variable "region" {
+  default = "us-east-1"
+}
+
+variable "admin_login" {
+  default   = "change-me-please"
+  sensitive = true
+}
+
+variable "db_password" {
+  type = string
+}
+`)
+
+	findings = detectTerraform("variables.tf", variables)
+	if len(findings) != 1 || findings[0].Key != "var.admin_login" || findings[0].LineNum != 6 {
+		t.Errorf("Expected a single var.admin_login finding on line 6, got %+v", findings)
+	}
+}
+
+func TestDetectTerraformStateLocatesValues(t *testing.T) {
+	// The password also appears in an earlier, non-sensitive attribute, and
+	// the sensitive output is an object spread over several lines
+	state := []byte(`{
+  "version": 4,
+  "outputs": {
+    "z_connection": {
+      "value": {
+        "host": "db.internal",
+        "password": "shared-password-value"
+      },
+      "type": ["object", {"host": "string", "password": "string"}],
+      "sensitive": true
+    },
+    "a_token": {
+      "value": "output-token-value",
+      "sensitive": true
+    }
+  },
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "random_password",
+      "name": "db",
+      "instances": [
+        {
+          "attributes": {
+            "keepers": {"seed": "shared-password-value"},
+            "result": "shared-password-value"
+          },
+          "sensitive_attributes": [
+            [{"type": "get_attr", "value": "result"}]
+          ]
+        }
+      ]
+    }
+  ]
+}`)
+
+	findings := detectTerraform("terraform.tfstate", state)
+	expected := []struct {
+		key  string
+		line int
+	}{
+		{"output.a_token", 13},
+		{"output.z_connection", 5},
+		{"random_password.db.result", 26},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %d: %+v", len(expected), len(findings), findings)
+	}
+	for i, want := range expected {
+		if findings[i].Key != want.key || findings[i].LineNum != want.line {
+			t.Errorf("Expected %s on line %d, got %s on line %d", want.key, want.line, findings[i].Key, findings[i].LineNum)
+		}
+	}
+	if f := findings[2]; string(state[f.Offset[0]:f.Offset[1]]) != "shared-password-value" {
+		t.Errorf("Expected the offset to cover the value, got %q", state[f.Offset[0]:f.Offset[1]])
+	}
+}
+
+func TestDetectTerraformVariablesLocatesNestedValues(t *testing.T) {
+	type location struct {
+		key  string
+		line int
+	}
+	tests := []struct {
+		path     string
+		content  string
+		expected []location
+	}{
+		{
+			path: "prod.tfvars",
+			content: `database = {
+  host     = "db.internal"
+  password = "nested-db-password"
+}
+api_tokens = [
+  "first-api-token",
+  "second-api-token",
+]
+`,
+			expected: []location{{"var.api_tokens.0", 6}, {"var.api_tokens.1", 7}, {"var.database.password", 3}},
+		},
+		{
+			path: "prod.tfvars.json",
+			content: `{
+  "database": {
+    "host": "db.internal",
+    "password": "nested-db-password"
+  },
+  "api_tokens": ["first-api-token"]
+}
+`,
+			expected: []location{{"var.api_tokens.0", 6}, {"var.database.password", 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			findings := detectTerraform(tt.path, []byte(tt.content))
+			if len(findings) != len(tt.expected) {
+				t.Fatalf("Expected %d findings, got %d: %+v", len(tt.expected), len(findings), findings)
+			}
+			for i, want := range tt.expected {
+				if findings[i].Key != want.key || findings[i].LineNum != want.line {
+					t.Errorf("Expected %s on line %d, got %s on line %d", want.key, want.line, findings[i].Key, findings[i].LineNum)
+				}
+			}
+		})
+	}
+}
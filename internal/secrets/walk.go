@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileDecision records what a scan does with a path found by the walk
+type FileDecision struct {
+	Path   string // Path of a file, or of a directory that was not descended into
+	Dir    bool   // Whether Path is a directory
+	Skip   string // Reason the path was skipped, empty when it is scanned
+	Detail string // What caused the skip: the ignore pattern, file size, encryption format or error
+}
+
+// ExplainDir walks a directory exactly as ScanDir does without scanning any
+// content, and calls fn with the decision made for each file and for each
+// directory that was not descended into
+func ExplainDir(rootPath string, ignorePatterns []string, fn func(FileDecision)) (Stats, error) {
+	stats := Stats{}
+	started := time.Now()
+	err := walkDir(rootPath, ignorePatterns, &stats, func(d FileDecision, _ []byte) {
+		fn(d)
+	})
+	stats.Duration = time.Since(started)
+	return stats, err
+}
+
+// walkDir walks a directory, decides for each file whether it is scanned or
+// skipped and records the outcome in stats. fn is called with every decision
+// and, for files to be scanned, their content.
+func walkDir(rootPath string, ignorePatterns []string, stats *Stats, fn func(FileDecision, []byte)) error {
+	return filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Record the path and carry on with the rest of the walk
+			op := OpStat
+			if info != nil && info.IsDir() {
+				op = OpList
+			}
+			scanErr := ScanError{Path: path, Op: op, Err: err}
+			stats.Errors = append(stats.Errors, scanErr)
+			fn(FileDecision{Path: path, Dir: op == OpList, Skip: SkipUnreadable, Detail: op + ": " + scanErr.Unwrap().Error()}, nil)
+			return nil
+		}
+
+		// Skip directories
+		if info.IsDir() {
+			// Check if directory should be ignored
+			if pattern := MatchIgnore(path, ignorePatterns); pattern != "" {
+				stats.skip(SkipIgnored)
+				fn(FileDecision{Path: path, Dir: true, Skip: SkipIgnored, Detail: "matches " + pattern}, nil)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		stats.FilesVisited++
+
+		// Check if file should be ignored
+		if pattern := MatchIgnore(path, ignorePatterns); pattern != "" {
+			stats.skip(SkipIgnored)
+			fn(FileDecision{Path: path, Skip: SkipIgnored, Detail: "matches " + pattern}, nil)
+			return nil
+		}
+
+		// Skip binary files and large files
+		if info.Size() > MaxFileSize {
+			stats.skip(SkipTooLarge)
+			fn(FileDecision{Path: path, Skip: SkipTooLarge, Detail: fmt.Sprintf("%d bytes, limit %d", info.Size(), MaxFileSize)}, nil)
+			return nil
+		}
+
+		// Read file content
+		content, err := os.ReadFile(path)
+		if err != nil {
+			stats.skip(SkipUnreadable)
+			scanErr := ScanError{Path: path, Op: OpRead, Err: err}
+			stats.Errors = append(stats.Errors, scanErr)
+			fn(FileDecision{Path: path, Skip: SkipUnreadable, Detail: OpRead + ": " + scanErr.Unwrap().Error()}, nil)
+			return nil
+		}
+
+		// Files encrypted as a whole are safe to commit and cannot be scanned
+		if format := fileEncryptionFormat(content); format != "" {
+			stats.skip(SkipEncrypted)
+			fn(FileDecision{Path: path, Skip: SkipEncrypted, Detail: format}, nil)
+			return nil
+		}
+
+		// Skip if likely binary file
+		if isBinary(content) {
+			stats.skip(SkipBinary)
+			fn(FileDecision{Path: path, Skip: SkipBinary, Detail: "NUL byte in the first 512 bytes"}, nil)
+			return nil
+		}
+
+		stats.FilesScanned++
+		stats.BytesScanned += int64(len(content))
+		fn(FileDecision{Path: path}, content)
+		return nil
+	})
+}
+
+// MatchIgnore returns the first ignore pattern matching the base name of a
+// path, or "" if none does
+func MatchIgnore(path string, ignorePatterns []string) string {
+	for _, pattern := range ignorePatterns {
+		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+			return pattern
+		}
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+// Package verify checks whether detected credentials are still live by
+// calling the service that issued them.
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// Status is the outcome of verifying a credential
+type Status string
+
+// Verification outcomes
+const (
+	StatusActive  Status = "verified-active" // The service accepted the credential
+	StatusRevoked Status = "revoked"         // The service rejected the credential
+	StatusUnknown Status = "unknown"         // The credential could not be checked
+)
+
+// Verifier checks a credential against the service that issued it
+type Verifier struct {
+	// BaseURL is the root of the service's API, configurable so that tests
+	// and private installations can point elsewhere
+	BaseURL string
+
+	// Check calls the service at baseURL with the secret
+	Check func(ctx context.Context, client *http.Client, baseURL, secret string) (Status, error)
+}
+
+// Verifiers maps rule IDs (see secrets.RuleID) to the verifier for the
+// credentials that rule detects.
+//
+// To support another credential type, add a verifier for its rule ID.
+var Verifiers = map[string]Verifier{
+	"github-token": {BaseURL: "https://api.github.com", Check: checkGitHub},
+	"slack-token":  {BaseURL: "https://slack.com/api", Check: checkSlack},
+}
+
+// Options configures a verification run
+type Options struct {
+	BaseURLs map[string]string // Base URL overrides by rule ID
+	Interval time.Duration     // Minimum delay between requests
+	Client   *http.Client      // HTTP client, defaults to one with a 10s timeout
+}
+
+// Session verifies findings one at a time, sharing the rate limit and the
+// cache of checked secrets between calls. It is used when findings are
+// streamed rather than collected; call Close when done.
+type Session struct {
+	opts    Options
+	client  *http.Client
+	timer   *time.Timer
+	last    time.Time // When the previous request was sent
+	checked map[string]Status
+}
+
+// NewSession starts a verification session
+func NewSession(opts Options) *Session {
+	s := &Session{
+		opts:    opts,
+		client:  opts.Client,
+		checked: make(map[string]Status),
+	}
+	if s.client == nil {
+		s.client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return s
+}
+
+// Close stops the session's rate limiter
+func (s *Session) Close() {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
+
+// Verify checks a finding with the verifier registered for its rule and
+// records the result in Finding.Verification
+func (s *Session) Verify(ctx context.Context, f *secrets.Finding) {
+	id := secrets.RuleID(f.Rule)
+	verifier, ok := Verifiers[id]
+	if !ok || f.Secret == "" {
+		f.Verification = string(StatusUnknown)
+		return
+	}
+
+	cacheKey := id + "\x00" + f.Secret
+	if status, ok := s.checked[cacheKey]; ok {
+		f.Verification = string(status)
+		return
+	}
+
+	// Space each request from the previous one, however long ago the
+	// session started or the previous finding arrived
+	if wait := s.opts.Interval - time.Since(s.last); !s.last.IsZero() && wait > 0 {
+		if s.timer == nil {
+			s.timer = time.NewTimer(wait)
+		} else {
+			s.timer.Reset(wait)
+		}
+		select {
+		case <-ctx.Done():
+			f.Verification = string(StatusUnknown)
+			return
+		case <-s.timer.C:
+		}
+	}
+	s.last = time.Now()
+
+	baseURL := verifier.BaseURL
+	if override, ok := s.opts.BaseURLs[id]; ok {
+		baseURL = override
+	}
+
+	status, err := verifier.Check(ctx, s.client, strings.TrimSuffix(baseURL, "/"), f.Secret)
+	if err != nil {
+		status = StatusUnknown
+	}
+	s.checked[cacheKey] = status
+	f.Verification = string(status)
+}
+
+// Findings verifies each finding with the verifier registered for its rule
+// and records the result in Finding.Verification. Findings without a
+// verifier or secret are marked unknown. Requests are spaced by
+// opts.Interval and each distinct secret is only checked once.
+func Findings(ctx context.Context, findings []secrets.Finding, opts Options) {
+	session := NewSession(opts)
+	defer session.Close()
+
+	for i := range findings {
+		session.Verify(ctx, &findings[i])
+	}
+}
+
+// checkGitHub calls GET /user, which succeeds for any valid token
+func checkGitHub(ctx context.Context, client *http.Client, baseURL, secret string) (Status, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/user", nil)
+	if err != nil {
+		return StatusUnknown, err
+	}
+	req.Header.Set("Authorization", "token "+secret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return StatusUnknown, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return StatusActive, nil
+	case http.StatusUnauthorized:
+		return StatusRevoked, nil
+	}
+	return StatusUnknown, fmt.Errorf("unexpected status from GitHub: %s", resp.Status)
+}
+
+// checkSlack calls auth.test, which reports whether a token is valid
+func checkSlack(ctx context.Context, client *http.Client, baseURL, secret string) (Status, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/auth.test", nil)
+	if err != nil {
+		return StatusUnknown, err
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return StatusUnknown, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return StatusUnknown, err
+	}
+
+	if result.OK {
+		return StatusActive, nil
+	}
+	switch result.Error {
+	case "invalid_auth", "token_revoked", "token_expired", "account_inactive", "not_authed":
+		return StatusRevoked, nil
+	}
+	return StatusUnknown, fmt.Errorf("unexpected error from Slack: %s", result.Error)
+}
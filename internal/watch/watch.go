@@ -0,0 +1,282 @@
+// Package watch keeps a secret scan up to date while files are edited,
+// re-scanning only the files that were created, modified or removed.
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/richiekrich/pipeline-guardian/internal/secrets"
+)
+
+// DefaultDebounce is how long a Watcher waits after the last change before
+// re-scanning, so that a burst of writes from an editor is scanned once
+const DefaultDebounce = 300 * time.Millisecond
+
+// Options configures a Watcher
+type Options struct {
+	Ignore   []string                   // Ignore patterns, applied as by secrets.ScanDir
+	Debounce time.Duration              // Quiet period before re-scanning, DefaultDebounce if zero
+	Filter   func(secrets.Finding) bool // Findings to keep, all if nil
+	OnError  func(error)                // Called with file system watcher errors, which are otherwise dropped
+}
+
+// Update describes how the findings in the watched paths changed with a scan
+type Update struct {
+	Initial  bool              // Whether this is the initial scan of every path
+	Paths    []string          // Paths that were re-scanned or removed, sorted
+	Appeared []secrets.Finding // Findings that are new since the previous scan
+	Resolved []secrets.Finding // Findings that are gone since the previous scan
+	Total    int               // Findings in the watched paths after the scan
+	Stats    secrets.Stats     // Coverage of the scan
+}
+
+// Watcher scans files and directories, then re-scans whatever changes in
+// them. Directories are watched recursively, except for those matching an
+// ignore pattern. Findings are matched across scans by fingerprint, so a
+// secret that only moves within its file is neither resolved nor new.
+type Watcher struct {
+	roots    []string
+	dirs     []string        // Roots that are directories
+	files    map[string]bool // Roots that are files
+	opts     Options
+	fsw      *fsnotify.Watcher
+	findings map[string][]secrets.Finding // Current findings by file
+}
+
+// New returns a Watcher for the given files and directories
+func New(roots []string, opts Options) *Watcher {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	w := &Watcher{opts: opts, files: make(map[string]bool), findings: make(map[string][]secrets.Finding)}
+	for _, root := range roots {
+		w.roots = append(w.roots, filepath.Clean(root))
+	}
+	return w
+}
+
+// Run scans every path, then re-scans changed paths until ctx is done. fn is
+// called with the initial scan and after each re-scan.
+func (w *Watcher) Run(ctx context.Context, fn func(Update)) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsw.Close()
+	w.fsw = fsw
+
+	// Start watching before the initial scan so that no change is missed
+	for _, root := range w.roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			w.dirs = append(w.dirs, root)
+			if err := w.addTree(root); err != nil {
+				return err
+			}
+		} else {
+			w.files[root] = true
+			if err := fsw.Add(filepath.Dir(root)); err != nil {
+				return err
+			}
+		}
+	}
+
+	update, err := w.scan(w.roots)
+	if err != nil {
+		return err
+	}
+	update.Initial = true
+	fn(update)
+
+	// Collect changed paths until none have changed for the debounce period
+	pending := make(map[string]bool)
+	timer := time.NewTimer(w.opts.Debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if !w.wanted(event.Name) || event.Op == fsnotify.Chmod {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := w.addTree(event.Name); err != nil {
+						w.error(err)
+					}
+				}
+			}
+			pending[event.Name] = true
+			timer.Reset(w.opts.Debounce)
+
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			w.error(err)
+
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			clear(pending)
+
+			update, err := w.scan(paths)
+			if err != nil {
+				return err
+			}
+			fn(update)
+		}
+	}
+}
+
+// scan re-scans paths, or forgets their findings if they no longer exist,
+// and returns the findings that changed
+func (w *Watcher) scan(paths []string) (Update, error) {
+	paths = outermost(paths)
+	update := Update{Paths: paths}
+
+	// Take out the current findings for the paths and everything below them
+	var previous []secrets.Finding
+	for file, findings := range w.findings {
+		for _, path := range paths {
+			if within(path, file) {
+				previous = append(previous, findings...)
+				delete(w.findings, file)
+				break
+			}
+		}
+	}
+
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+
+	var current []secrets.Finding
+	if len(existing) > 0 {
+		stats, err := secrets.ScanPathsFunc(existing, w.opts.Ignore, func(f secrets.Finding) {
+			if w.opts.Filter == nil || w.opts.Filter(f) {
+				current = append(current, f)
+				w.findings[f.File] = append(w.findings[f.File], f)
+			}
+		})
+		if err != nil {
+			return update, err
+		}
+		update.Stats = stats
+	}
+
+	update.Appeared, update.Resolved = diff(previous, current)
+	for _, findings := range w.findings {
+		update.Total += len(findings)
+	}
+	return update, nil
+}
+
+// addTree watches a directory and every directory below it that is not
+// ignored
+func (w *Watcher) addTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are reported by the scan itself
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && secrets.MatchIgnore(path, w.opts.Ignore) != "" {
+			return filepath.SkipDir
+		}
+		return w.fsw.Add(path)
+	})
+}
+
+// wanted reports whether a path belongs to the watched roots. Watching a
+// file means watching its directory, whose other entries are not wanted.
+func (w *Watcher) wanted(path string) bool {
+	if w.files[path] {
+		return true
+	}
+	for _, dir := range w.dirs {
+		if within(dir, path) {
+			return secrets.MatchIgnore(path, w.opts.Ignore) == "" || !isDir(path)
+		}
+	}
+	return false
+}
+
+// error reports a watcher error to the caller, if it asked for them
+func (w *Watcher) error(err error) {
+	if w.opts.OnError != nil {
+		w.opts.OnError(err)
+	}
+}
+
+// within reports whether path is root or below it
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// outermost sorts paths and drops those below another of the paths, which
+// are scanned with it
+func outermost(paths []string) []string {
+	sort.Strings(paths)
+	var kept []string
+	for _, path := range paths {
+		if !slices.ContainsFunc(kept, func(parent string) bool { return within(parent, path) }) {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// diff returns the findings only in current and those only in previous,
+// matching findings by fingerprint
+func diff(previous, current []secrets.Finding) (appeared, resolved []secrets.Finding) {
+	counts := make(map[string]int)
+	for _, f := range previous {
+		counts[f.Fingerprint()]++
+	}
+	for _, f := range current {
+		if counts[f.Fingerprint()] > 0 {
+			counts[f.Fingerprint()]--
+		} else {
+			appeared = append(appeared, f)
+		}
+	}
+	for _, f := range previous {
+		if counts[f.Fingerprint()] > 0 {
+			counts[f.Fingerprint()]--
+			resolved = append(resolved, f)
+		}
+	}
+	return appeared, resolved
+}